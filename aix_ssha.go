@@ -0,0 +1,100 @@
+package passwd
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+type AIXSSHA struct {
+	Passwd
+}
+
+// Make an AIX {ssha1} password instance.
+func NewAIXSSHA1Passwd() PasswdInterface {
+	return newAIXSSHAPasswd(AIX_SSHA1_MAGIC)
+}
+
+// Make an AIX {ssha256} password instance.
+func NewAIXSSHA256Passwd() PasswdInterface {
+	return newAIXSSHAPasswd(AIX_SSHA256_MAGIC)
+}
+
+// Make an AIX {ssha512} password instance.
+func NewAIXSSHA512Passwd() PasswdInterface {
+	return newAIXSSHAPasswd(AIX_SSHA512_MAGIC)
+}
+
+// Make an AIX salted SHA password instance for the magic.
+func newAIXSSHAPasswd(magic string) *AIXSSHA {
+	m := new(AIXSSHA)
+	m.Magic = magic
+	m.SetCost(8)
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Sets the cost, which is the base 2 logarithm of the iteration count.
+func (a *AIXSSHA) SetCost(cost int) {
+	a.Params = fmt.Sprintf("cost=%d", cost)
+}
+
+// Get the hash function for the magic.
+func (a *AIXSSHA) hashFunc() (h func() hash.Hash, size int, err error) {
+	switch a.Magic {
+	case AIX_SSHA1_MAGIC:
+		h, size = sha1.New, SHA1_SIZE
+	case AIX_SSHA256_MAGIC:
+		h, size = sha256.New, SHA256_SIZE
+	case AIX_SSHA512_MAGIC:
+		h, size = sha512.New, SHA512_SIZE
+	default:
+		err = errors.New("unsupported AIX salted SHA hash")
+	}
+	return
+}
+
+// Generate a salt encoded with the crypt base64 alphabet.
+func (a *AIXSSHA) GenerateSalt() ([]byte, error) {
+	return generateIota64Salt(uint(a.SaltLength))
+}
+
+// Hash a password with salt using the AIX salted SHA standard, which is
+// PBKDF2 with 2 to the power of cost iterations.
+func (a *AIXSSHA) Hash(password []byte, salt []byte, cost int) (hash []byte, err error) {
+	h, size, err := a.hashFunc()
+	if err != nil {
+		return
+	}
+	if cost < 4 || cost > 31 {
+		err = errors.New("AIX salted SHA cost must be between 4 and 31")
+		return
+	}
+	key := pbkdf2.Key(password, salt, 1<<uint(cost), size, h)
+
+	// Create hash with result, the cost is always two digits.
+	hash = []byte(fmt.Sprintf("%s%02d$%s$", a.Magic, cost, salt))
+	hash = append(hash, AIXBase64Encode(key)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with AIX salted SHA.
+func (a *AIXSSHA) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	// Parse cost from parameter.
+	var cost int
+	_, err = fmt.Sscanf(a.Params, "cost=%d", &cost)
+	if err != nil {
+		return
+	}
+
+	// Compute hash.
+	hash, err = a.Hash(password, salt, cost)
+	return
+}
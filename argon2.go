@@ -0,0 +1,400 @@
+package passwd
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/blake2b"
+)
+
+type Argon2 struct {
+	Passwd
+	// Length of the derived key in bytes.
+	KeyLength uint32
+}
+
+// Argon2 variants, matching the type numbers used in the spec.
+const (
+	argon2d = iota
+	argon2i
+	argon2id
+)
+
+// Argon2 versions, the original version overwrites blocks on later passes.
+const (
+	ARGON2_VERSION_10 = 0x10
+	ARGON2_VERSION_13 = 0x13
+)
+
+// Make an Argon2id password instance.
+func NewArgon2idPasswd() PasswdInterface {
+	return newArgon2Passwd(ARGON2ID_MAGIC)
+}
+
+// Make an Argon2i password instance.
+func NewArgon2iPasswd() PasswdInterface {
+	return newArgon2Passwd(ARGON2I_MAGIC)
+}
+
+// Make an Argon2d password instance.
+func NewArgon2dPasswd() PasswdInterface {
+	return newArgon2Passwd(ARGON2D_MAGIC)
+}
+
+// Make an Argon2 password instance for the provided variant magic.
+func newArgon2Passwd(magic string) *Argon2 {
+	m := new(Argon2)
+	m.Magic = magic
+	m.SetArgon2Params(65536, 3, 4)
+	m.KeyLength = 32
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Sets the Argon2 params using integers, memory is in KiB.
+func (a *Argon2) SetArgon2Params(memory, time uint32, threads uint8) {
+	a.Params = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", ARGON2_VERSION_13, memory, time, threads)
+}
+
+// Decode Argon2 params. Params without a version are from before version 1.3.
+func (a *Argon2) DecodeArgon2Params() (version, memory, time uint32, threads uint8, err error) {
+	params := a.Params
+	version = ARGON2_VERSION_10
+	if strings.HasPrefix(params, "v=") {
+		s := strings.SplitN(params, "$", 2)
+		if len(s) != 2 {
+			err = errors.New("missing Argon2 cost parameters")
+			return
+		}
+		_, err = fmt.Sscanf(s[0], "v=%d", &version)
+		if err != nil {
+			return
+		}
+		params = s[1]
+	}
+	_, err = fmt.Sscanf(params, "m=%d,t=%d,p=%d", &memory, &time, &threads)
+	return
+}
+
+// Generate a salt encoded with standard base64 without padding.
+func (a *Argon2) GenerateSalt() ([]byte, error) {
+	rawSalt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, base64.RawStdEncoding.EncodedLen(len(rawSalt)))
+	base64.RawStdEncoding.Encode(salt, rawSalt)
+	return salt, nil
+}
+
+// Hash a password with salt using the Argon2 standard in the PHC string format.
+func (a *Argon2) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	var mode int
+	switch a.Magic {
+	case ARGON2D_MAGIC:
+		mode = argon2d
+	case ARGON2I_MAGIC:
+		mode = argon2i
+	case ARGON2ID_MAGIC:
+		mode = argon2id
+	default:
+		err = errors.New("unsupported Argon2 variant")
+		return
+	}
+	version, memory, time, threads, err := a.DecodeArgon2Params()
+	if err != nil {
+		return
+	}
+	if version != ARGON2_VERSION_10 && version != ARGON2_VERSION_13 {
+		err = errors.New("unsupported Argon2 version")
+		return
+	}
+	if time < 1 || threads < 1 || a.KeyLength < 4 {
+		err = errors.New("invalid Argon2 parameters")
+		return
+	}
+
+	rawSalt, err := base64.RawStdEncoding.DecodeString(string(salt))
+	if err != nil {
+		return
+	}
+
+	// The x/crypto implementation is optimized, but only supports the current version of i and id.
+	var key []byte
+	switch {
+	case version == ARGON2_VERSION_13 && mode == argon2i:
+		key = argon2.Key(password, rawSalt, time, memory, threads, a.KeyLength)
+	case version == ARGON2_VERSION_13 && mode == argon2id:
+		key = argon2.IDKey(password, rawSalt, time, memory, threads, a.KeyLength)
+	default:
+		key = argon2Key(mode, version, password, rawSalt, time, memory, uint32(threads), a.KeyLength)
+	}
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%s$%s$", a.Magic, a.Params, salt))
+	hash = append(hash, base64.RawStdEncoding.EncodeToString(key)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with Argon2.
+func (a *Argon2) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// The following is a portable Argon2 implementation based on golang.org/x/crypto/argon2,
+// extended to support Argon2d and version 1.0 which are needed for verifying older hashes.
+
+const (
+	argon2BlockLength = 128
+	argon2SyncPoints  = 4
+)
+
+type argon2Block [argon2BlockLength]uint64
+
+// Derive a key with Argon2.
+func argon2Key(mode int, version uint32, password, salt []byte, time, memory, threads, keyLen uint32) []byte {
+	// Compute the initial hash of all parameters.
+	var h0 [blake2b.Size + 8]byte
+	var params [24]byte
+	b2, _ := blake2b.New512(nil)
+	binary.LittleEndian.PutUint32(params[0:4], threads)
+	binary.LittleEndian.PutUint32(params[4:8], keyLen)
+	binary.LittleEndian.PutUint32(params[8:12], memory)
+	binary.LittleEndian.PutUint32(params[12:16], time)
+	binary.LittleEndian.PutUint32(params[16:20], version)
+	binary.LittleEndian.PutUint32(params[20:24], uint32(mode))
+	b2.Write(params[:])
+	for _, v := range [][]byte{password, salt, nil, nil} {
+		binary.LittleEndian.PutUint32(params[:4], uint32(len(v)))
+		b2.Write(params[:4])
+		b2.Write(v)
+	}
+	b2.Sum(h0[:0])
+
+	// Memory is rounded down to a multiple of the sync points in each lane.
+	memory = memory / (argon2SyncPoints * threads) * (argon2SyncPoints * threads)
+	if memory < 2*argon2SyncPoints*threads {
+		memory = 2 * argon2SyncPoints * threads
+	}
+	lanes := memory / threads
+	segments := lanes / argon2SyncPoints
+
+	// The first two blocks of each lane are derived from the initial hash.
+	B := make([]argon2Block, memory)
+	var block0 [1024]byte
+	for lane := uint32(0); lane < threads; lane++ {
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(h0[blake2b.Size:], i)
+			argon2Blake2bHash(block0[:], h0[:])
+			for j := range B[lane*lanes+i] {
+				B[lane*lanes+i][j] = binary.LittleEndian.Uint64(block0[j*8:])
+			}
+		}
+	}
+
+	// Fill each segment of each lane, synchronizing the lanes at each slice.
+	processSegment := func(n, slice, lane uint32, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		// Argon2i and the first half of the first pass of Argon2id use data-independent addressing.
+		independent := mode == argon2i || (mode == argon2id && n == 0 && slice < argon2SyncPoints/2)
+		var addresses, in, zero argon2Block
+		if independent {
+			in[0] = uint64(n)
+			in[1] = uint64(lane)
+			in[2] = uint64(slice)
+			in[3] = uint64(memory)
+			in[4] = uint64(time)
+			in[5] = uint64(mode)
+		}
+
+		index := uint32(0)
+		if n == 0 && slice == 0 {
+			// The first two blocks were already generated.
+			index = 2
+			if independent {
+				in[6]++
+				argon2ProcessBlock(&addresses, &in, &zero, false)
+				argon2ProcessBlock(&addresses, &addresses, &zero, false)
+			}
+		}
+
+		offset := lane*lanes + slice*segments + index
+		for index < segments {
+			prev := offset - 1
+			if index == 0 && slice == 0 {
+				// Last block in the lane.
+				prev += lanes
+			}
+			var random uint64
+			if independent {
+				if index%argon2BlockLength == 0 {
+					in[6]++
+					argon2ProcessBlock(&addresses, &in, &zero, false)
+					argon2ProcessBlock(&addresses, &addresses, &zero, false)
+				}
+				random = addresses[index%argon2BlockLength]
+			} else {
+				random = B[prev][0]
+			}
+			ref := argon2IndexAlpha(random, lanes, segments, threads, n, slice, lane, index)
+
+			// Version 1.0 overwrites blocks on later passes instead of XORing them.
+			argon2ProcessBlock(&B[offset], &B[prev], &B[ref], version != ARGON2_VERSION_10 && n != 0)
+			index, offset = index+1, offset+1
+		}
+	}
+	for n := uint32(0); n < time; n++ {
+		for slice := uint32(0); slice < argon2SyncPoints; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < threads; lane++ {
+				wg.Add(1)
+				go processSegment(n, slice, lane, &wg)
+			}
+			wg.Wait()
+		}
+	}
+
+	// XOR the last block of each lane, and hash it to the key length.
+	for lane := uint32(0); lane < threads-1; lane++ {
+		for i, v := range B[lane*lanes+lanes-1] {
+			B[memory-1][i] ^= v
+		}
+	}
+	for i, v := range B[memory-1] {
+		binary.LittleEndian.PutUint64(block0[i*8:], v)
+	}
+	key := make([]byte, keyLen)
+	argon2Blake2bHash(key, block0[:])
+	return key
+}
+
+// Compute the variable length hash H' from the spec.
+func argon2Blake2bHash(out []byte, in []byte) {
+	var buffer [blake2b.Size]byte
+	binary.LittleEndian.PutUint32(buffer[:4], uint32(len(out)))
+
+	// Short outputs are a single blake2b hash of that size.
+	if len(out) <= blake2b.Size {
+		b2, _ := blake2b.New(len(out), nil)
+		b2.Write(buffer[:4])
+		b2.Write(in)
+		b2.Sum(out[:0])
+		return
+	}
+
+	// Longer outputs chain 64 byte hashes, using the first 32 bytes of each.
+	outLen := len(out)
+	b2, _ := blake2b.New512(nil)
+	b2.Write(buffer[:4])
+	b2.Write(in)
+	b2.Sum(buffer[:0])
+	copy(out, buffer[:32])
+	out = out[32:]
+	for len(out) > blake2b.Size {
+		b2.Reset()
+		b2.Write(buffer[:])
+		b2.Sum(buffer[:0])
+		copy(out, buffer[:32])
+		out = out[32:]
+	}
+
+	// The final hash is sized to the remainder.
+	if outLen%blake2b.Size > 0 {
+		r := ((outLen + 31) / 32) - 2
+		b2, _ = blake2b.New(outLen-32*r, nil)
+	} else {
+		b2.Reset()
+	}
+	b2.Write(buffer[:])
+	b2.Sum(out[:0])
+}
+
+// Compute the reference block index from the pseudo-random value.
+func argon2IndexAlpha(rand uint64, lanes, segments, threads, n, slice, lane, index uint32) uint32 {
+	refLane := uint32(rand>>32) % threads
+	if n == 0 && slice == 0 {
+		refLane = lane
+	}
+	m, s := 3*segments, ((slice+1)%argon2SyncPoints)*segments
+	if lane == refLane {
+		m += index
+	}
+	if n == 0 {
+		m, s = slice*segments, 0
+		if slice == 0 || lane == refLane {
+			m += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		m--
+	}
+
+	// Map the random value non-uniformly to favor recent blocks.
+	p := rand & 0xFFFFFFFF
+	p = (p * p) >> 32
+	p = (p * uint64(m)) >> 32
+	return refLane*lanes + uint32((uint64(s)+uint64(m)-(p+1))%uint64(lanes))
+}
+
+// The compression function G, which mixes two blocks into the output.
+func argon2ProcessBlock(out, in1, in2 *argon2Block, xor bool) {
+	var t argon2Block
+	for i := range t {
+		t[i] = in1[i] ^ in2[i]
+	}
+
+	// Apply the blake2b based permutation to rows, then columns.
+	for i := 0; i < argon2BlockLength; i += 16 {
+		argon2Blamka(&t, [16]int{
+			i + 0, i + 1, i + 2, i + 3, i + 4, i + 5, i + 6, i + 7,
+			i + 8, i + 9, i + 10, i + 11, i + 12, i + 13, i + 14, i + 15,
+		})
+	}
+	for i := 0; i < argon2BlockLength/8; i += 2 {
+		argon2Blamka(&t, [16]int{
+			i, i + 1, 16 + i, 16 + i + 1, 32 + i, 32 + i + 1, 48 + i, 48 + i + 1,
+			64 + i, 64 + i + 1, 80 + i, 80 + i + 1, 96 + i, 96 + i + 1, 112 + i, 112 + i + 1,
+		})
+	}
+
+	for i := range t {
+		if xor {
+			out[i] ^= in1[i] ^ in2[i] ^ t[i]
+		} else {
+			out[i] = in1[i] ^ in2[i] ^ t[i]
+		}
+	}
+}
+
+// Apply the blake2b round with multiplication to 16 words of the block.
+func argon2Blamka(t *argon2Block, v [16]int) {
+	g := func(a, b, c, d int) {
+		t[a] += t[b] + 2*uint64(uint32(t[a]))*uint64(uint32(t[b]))
+		t[d] = bits.RotateLeft64(t[d]^t[a], -32)
+		t[c] += t[d] + 2*uint64(uint32(t[c]))*uint64(uint32(t[d]))
+		t[b] = bits.RotateLeft64(t[b]^t[c], -24)
+		t[a] += t[b] + 2*uint64(uint32(t[a]))*uint64(uint32(t[b]))
+		t[d] = bits.RotateLeft64(t[d]^t[a], -16)
+		t[c] += t[d] + 2*uint64(uint32(t[c]))*uint64(uint32(t[d]))
+		t[b] = bits.RotateLeft64(t[b]^t[c], -63)
+	}
+	g(v[0], v[4], v[8], v[12])
+	g(v[1], v[5], v[9], v[13])
+	g(v[2], v[6], v[10], v[14])
+	g(v[3], v[7], v[11], v[15])
+	g(v[0], v[5], v[10], v[15])
+	g(v[1], v[6], v[11], v[12])
+	g(v[2], v[7], v[8], v[13])
+	g(v[3], v[4], v[9], v[14])
+}
@@ -0,0 +1,168 @@
+package passwd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+type Bcrypt struct {
+	Passwd
+}
+
+// The magic text encrypted 64 times with the expensive key schedule.
+const bcryptMagicCipherData = "OrpheanBeholderScryDoubt"
+
+// Make a bcrypt password instance.
+func NewBcryptPasswd() PasswdInterface {
+	return NewBcryptVariantPasswd(BCRYPT_MAGIC)
+}
+
+// Make a bcrypt password instance for one of the $2a$, $2b$, $2x$, or $2y$ variants.
+func NewBcryptVariantPasswd(magic string) PasswdInterface {
+	m := new(Bcrypt)
+	m.Magic = magic
+	m.Params = "cost=10"
+	// The salt is 16 raw bytes, encoded to 22 characters.
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Sets the cost, which is the base 2 logarithm of the iteration count.
+func (a *Bcrypt) SetCost(cost int) {
+	a.Params = fmt.Sprintf("cost=%d", cost)
+}
+
+// Generate a salt encoded with the bcrypt base64 alphabet.
+func (a *Bcrypt) GenerateSalt() ([]byte, error) {
+	rawSalt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	return BcryptBase64Encode(rawSalt), nil
+}
+
+// Get the sign extension bug and safety flags for the variant, matching crypt_blowfish.
+func (a *Bcrypt) flags() (bug bool, safety bool, err error) {
+	switch a.Magic {
+	case BCRYPT_A_MAGIC:
+		safety = true
+	case BCRYPT_X_MAGIC:
+		bug = true
+	case BCRYPT_MAGIC, BCRYPT_Y_MAGIC:
+	default:
+		err = errors.New("unsupported bcrypt variant")
+	}
+	return
+}
+
+// Expand the password into the 18 words XORed into the blowfish P-array.
+// The $2x$ variant reproduces the historical sign extension bug of
+// crypt_blowfish, where bytes with the high bit set were sign extended
+// before being ORed into the word, clobbering the prior bytes. The $2a$
+// variant computes the correct key, but to stop collisions with the buggy
+// key it flips a bit of the initial state whenever the bug would have
+// produced a non-benign difference.
+func (a *Bcrypt) expandKey(password []byte, bug, safety bool) (expanded []byte, initial []byte) {
+	// The key includes the terminating null, and is cycled to fill the words.
+	key := append(password[:len(password):len(password)], 0)
+
+	expanded = make([]byte, 72)
+	var sign, diff uint32
+	ptr := 0
+	for i := 0; i < 18; i++ {
+		var correct, buggy uint32
+		for j := 0; j < 4; j++ {
+			correct = correct<<8 | uint32(key[ptr])
+			buggy = buggy<<8 | uint32(int32(int8(key[ptr])))
+			if j != 0 {
+				sign |= buggy & 0x80
+			}
+			ptr = (ptr + 1) % len(key)
+		}
+		diff |= correct ^ buggy
+		if bug {
+			binary.BigEndian.PutUint32(expanded[i*4:], buggy)
+		} else {
+			binary.BigEndian.PutUint32(expanded[i*4:], correct)
+		}
+	}
+
+	// The initial key only differs by the safety bit for $2a$.
+	initial = append([]byte{}, expanded...)
+	if safety && diff == 0 && sign != 0 {
+		initial[1] ^= 0x01
+	}
+	return
+}
+
+// Hash a password with salt using the bcrypt standard.
+func (a *Bcrypt) Hash(password []byte, salt []byte, cost int) (hash []byte, err error) {
+	bug, safety, err := a.flags()
+	if err != nil {
+		return
+	}
+	if cost < 4 || cost > 31 {
+		err = errors.New("bcrypt cost must be between 4 and 31")
+		return
+	}
+
+	// Salt is 22 characters, which decodes to 16 bytes.
+	if len(salt) < 22 {
+		err = errors.New("bcrypt salt must be 22 characters")
+		return
+	}
+	rawSalt, err := BcryptBase64Decode(salt[:22])
+	if err != nil {
+		return
+	}
+
+	// Only the first 72 bytes of the key are used, which includes the null.
+	if len(password) > 72 {
+		password = password[:72]
+	}
+	expanded, initial := a.expandKey(password, bug, safety)
+
+	// Setup the expensive key schedule, the first expansion mixes in the salt.
+	c, err := blowfish.NewSaltedCipher(initial, rawSalt)
+	if err != nil {
+		return
+	}
+	rounds := uint64(1) << uint(cost)
+	for i := uint64(0); i < rounds; i++ {
+		blowfish.ExpandKey(expanded, c)
+		blowfish.ExpandKey(rawSalt, c)
+	}
+
+	// Encrypt the magic text 64 times.
+	cipherData := []byte(bcryptMagicCipherData)
+	for i := 0; i < 24; i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(cipherData[i:i+8], cipherData[i:i+8])
+		}
+	}
+
+	// Create hash with result, the salt is re-encoded to normalize the unused bits.
+	hash = []byte(fmt.Sprintf("%s%02d$", a.Magic, cost))
+	hash = append(hash, BcryptBase64Encode(rawSalt)...)
+	hash = append(hash, BcryptBase64Encode(cipherData[:BCRYPT_SIZE])...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with bcrypt.
+func (a *Bcrypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	// Parse cost from parameter.
+	var cost int
+	_, err = fmt.Sscanf(a.Params, "cost=%d", &cost)
+	if err != nil {
+		return
+	}
+
+	// Compute hash.
+	hash, err = a.Hash(password, salt, cost)
+	return
+}
@@ -0,0 +1,132 @@
+package passwd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Passlib's bcrypt-sha256, which pre-hashes the password with SHA256 so
+// passwords longer than bcrypt's 72 byte limit are not truncated.
+type BcryptSHA256 struct {
+	Passwd
+}
+
+// Make a passlib bcrypt-sha256 password instance.
+func NewBcryptSHA256Passwd() PasswdInterface {
+	m := new(BcryptSHA256)
+	m.Magic = BCRYPT_SHA256_MAGIC
+	m.SetBcryptSHA256Params(2, "2b", 12)
+	// The salt is 16 raw bytes, encoded to 22 characters.
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Sets the params. Version 2 uses the v=<version>,t=<ident>,r=<cost> format
+// with an HMAC pre-hash, while version 1 uses the <ident>,<cost> format.
+func (a *BcryptSHA256) SetBcryptSHA256Params(version int, ident string, cost int) {
+	a.Params = bcryptSHA256Params(version, ident, cost)
+}
+
+// Format the params for the version.
+func bcryptSHA256Params(version int, ident string, cost int) string {
+	if version == 1 {
+		return fmt.Sprintf("%s,%d", ident, cost)
+	}
+	return fmt.Sprintf("v=%d,t=%s,r=%d", version, ident, cost)
+}
+
+// Decode the params.
+func (a *BcryptSHA256) DecodeBcryptSHA256Params() (version int, ident string, cost int, err error) {
+	s := strings.Split(a.Params, ",")
+	if len(s) == 2 {
+		version = 1
+		ident = s[0]
+		cost, err = strconv.Atoi(s[1])
+		return
+	}
+	if len(s) != 3 || !strings.HasPrefix(s[0], "v=") || !strings.HasPrefix(s[1], "t=") || !strings.HasPrefix(s[2], "r=") {
+		err = errors.New("invalid bcrypt-sha256 params")
+		return
+	}
+	version, err = strconv.Atoi(s[0][2:])
+	if err != nil {
+		return
+	}
+	if version != 2 {
+		err = errors.New("unsupported bcrypt-sha256 version")
+		return
+	}
+	ident = s[1][2:]
+	cost, err = strconv.Atoi(s[2][2:])
+	return
+}
+
+// Generate a salt encoded with the bcrypt base64 alphabet.
+func (a *BcryptSHA256) GenerateSalt() ([]byte, error) {
+	rawSalt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	return BcryptBase64Encode(rawSalt), nil
+}
+
+// Hash a password with salt using passlib's bcrypt-sha256.
+func (a *BcryptSHA256) Hash(password []byte, salt []byte, version int, ident string, cost int) (hash []byte, err error) {
+	// Passlib only supports the $2a$ ident for version 1, and $2b$ for version 2.
+	if (version != 1 || ident != "2a") && (version != 2 || ident != "2b") {
+		err = errors.New("unsupported bcrypt-sha256 version and ident")
+		return
+	}
+
+	// Normalize the salt, as the HMAC key must match the salt in the hash.
+	if len(salt) < 22 {
+		err = errors.New("bcrypt salt must be 22 characters")
+		return
+	}
+	rawSalt, err := BcryptBase64Decode(salt[:22])
+	if err != nil {
+		return
+	}
+	salt = BcryptBase64Encode(rawSalt)
+
+	// Version 1 is a plain SHA256, while version 2 is an HMAC keyed by the salt.
+	var digest []byte
+	if version == 1 {
+		sum := sha256.Sum256(password)
+		digest = sum[:]
+	} else {
+		h := hmac.New(sha256.New, salt)
+		h.Write(password)
+		digest = h.Sum(nil)
+	}
+	key := []byte(base64.StdEncoding.EncodeToString(digest))
+
+	crypt := NewBcryptVariantPasswd("$" + ident + "$").(*Bcrypt)
+	bcryptHash, err := crypt.Hash(key, salt, cost)
+	if err != nil {
+		return
+	}
+
+	// Create hash with the bcrypt checksum, which follows the salt.
+	params := bcryptSHA256Params(version, ident, cost)
+	hash = []byte(fmt.Sprintf("%s%s$%s$%s", a.Magic, params, salt, bcryptHash[len(bcryptHash)-31:]))
+	return
+}
+
+// Override the passwd hash with salt function to hash with bcrypt-sha256.
+func (a *BcryptSHA256) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	version, ident, cost, err := a.DecodeBcryptSHA256Params()
+	if err != nil {
+		return
+	}
+
+	hash, err = a.Hash(password, salt, version, ident, cost)
+	return
+}
@@ -0,0 +1,135 @@
+package passwd
+
+import (
+	"bytes"
+)
+
+type BigCrypt struct {
+	Passwd
+}
+
+type Crypt16 struct {
+	Passwd
+}
+
+// Make a bigcrypt password instance.
+func NewBigCryptPasswd() PasswdInterface {
+	m := new(BigCrypt)
+	m.Magic = BIG_CRYPT_MAGIC
+	// The salt is 2 characters, which is 12 bits.
+	m.SaltLength = 2
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a crypt16 password instance.
+func NewCrypt16Passwd() PasswdInterface {
+	m := new(Crypt16)
+	m.Magic = CRYPT16_MAGIC
+	// The salt is 2 characters, which is 12 bits.
+	m.SaltLength = 2
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Generate a 2 character salt.
+func (a *BigCrypt) GenerateSalt() ([]byte, error) {
+	return generateIota64Salt(uint(a.SaltLength))
+}
+
+// Hash a password with salt using the bigcrypt standard.
+func (a *BigCrypt) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	saltVal, err := desDecodeSalt(salt)
+	if err != nil {
+		return
+	}
+	hash = append([]byte(a.Magic), salt[:2]...)
+
+	// Each 8 characters of the password is hashed as a DES crypt segment, up to 16 segments.
+	segments := (len(password) + 7) / 8
+	if segments == 0 {
+		segments = 1
+	} else if segments > 16 {
+		segments = 16
+	}
+	for i := 0; i < segments; i++ {
+		ks := desKeySchedule(desKeyFromPassword(password[i*8:]))
+		block := desCrypt(&ks, 0, desSaltBits(saltVal), 25)
+		b64 := DESBase64Encode(block)
+		hash = append(hash, b64...)
+
+		// The next segment is salted with the start of this segment.
+		saltVal, _ = desDecodeSalt(b64)
+	}
+	return
+}
+
+// Override the passwd hash with salt function to hash with bigcrypt.
+func (a *BigCrypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// Check a password against a bigcrypt hash. Two segment bigcrypt hashes are
+// the same length as crypt16 hashes, so those are checked with both.
+func (a *BigCrypt) CheckPassword(hash []byte, password []byte) (bool, error) {
+	// The salt is the first 2 characters of the hash.
+	newHash, err := a.Hash(password, hash)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(hash, newHash) {
+		return true, nil
+	}
+
+	// Fallback to crypt16 for the shared length.
+	if len(hash) == 24 {
+		passwd := NewCrypt16Passwd().(*Crypt16)
+		newHash, err = passwd.Hash(password, hash)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(hash, newHash), nil
+	}
+	return false, nil
+}
+
+// Generate a 2 character salt.
+func (a *Crypt16) GenerateSalt() ([]byte, error) {
+	return generateIota64Salt(uint(a.SaltLength))
+}
+
+// Hash a password with salt using the crypt16 standard.
+func (a *Crypt16) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	saltVal, err := desDecodeSalt(salt)
+	if err != nil {
+		return
+	}
+	saltBits := desSaltBits(saltVal)
+
+	// The first 8 characters are encrypted 20 times.
+	ks := desKeySchedule(desKeyFromPassword(password))
+	block1 := desCrypt(&ks, 0, saltBits, 20)
+
+	// The second 8 characters are encrypted 5 times with the same salt.
+	var second []byte
+	if len(password) > 8 {
+		second = password[8:]
+	}
+	ks = desKeySchedule(desKeyFromPassword(second))
+	block2 := desCrypt(&ks, 0, saltBits, 5)
+
+	// Create hash with result.
+	hash = append([]byte(a.Magic), salt[:2]...)
+	hash = append(hash, DESBase64Encode(block1)...)
+	hash = append(hash, DESBase64Encode(block2)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with crypt16.
+func (a *Crypt16) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
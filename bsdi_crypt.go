@@ -0,0 +1,84 @@
+package passwd
+
+import (
+	"errors"
+	"fmt"
+)
+
+type BSDiCrypt struct {
+	Passwd
+}
+
+// Make a BSDi extended DES crypt password instance.
+func NewBSDiCryptPasswd() PasswdInterface {
+	m := new(BSDiCrypt)
+	m.Magic = BSDI_CRYPT_MAGIC
+	m.Params = "rounds=725"
+	// The salt is 4 characters, which is 24 bits.
+	m.SaltLength = 4
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Generate a 4 character salt.
+func (a *BSDiCrypt) GenerateSalt() ([]byte, error) {
+	return generateIota64Salt(uint(a.SaltLength))
+}
+
+// Hash a password with salt using the BSDi extended DES crypt standard.
+func (a *BSDiCrypt) Hash(password []byte, salt []byte, iterations uint32) (hash []byte, err error) {
+	if len(salt) < 4 || !validIota64(salt[:4]) {
+		err = errors.New("BSDi crypt salt must be 4 characters")
+		return
+	}
+	salt = salt[:4]
+	saltVal := Base64Uint32Decode(salt, 24)
+
+	// Rounds are encoded in 24 bits.
+	if iterations == 0 {
+		err = errors.New("BSDi crypt rounds must be greater than 0")
+		return
+	}
+	rounds := Base64Uint32Encode(iterations, 24)
+	if len(rounds) == 0 {
+		err = errors.New("BSDi crypt rounds must fit in 24 bits")
+		return
+	}
+
+	// Setup the key with the first 8 characters of the password.
+	key := desKeyFromPassword(password)
+	ks := desKeySchedule(key)
+
+	// Unlike traditional DES crypt, the whole password is used by folding each
+	// additional 8 characters into the key. The key is encrypted with itself,
+	// and the next 8 characters are XORed into the result.
+	for i := 8; i < len(password); i += 8 {
+		key = desCrypt(&ks, key, 0, 1) ^ desKeyFromPassword(password[i:])
+		ks = desKeySchedule(key)
+	}
+
+	// Encrypt an empty block with the salt for the defined number of rounds.
+	block := desCrypt(&ks, 0, desSaltBits(saltVal), iterations)
+
+	// Create hash with result.
+	hash = []byte(a.Magic)
+	hash = append(hash, rounds...)
+	hash = append(hash, salt...)
+	hash = append(hash, DESBase64Encode(block)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with BSDi crypt.
+func (a *BSDiCrypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	// Parse iterations from parameter.
+	var iterations uint32
+	_, err = fmt.Sscanf(a.Params, "rounds=%d", &iterations)
+	if err != nil {
+		return
+	}
+
+	// Compute hash.
+	hash, err = a.Hash(password, salt, iterations)
+	return
+}
@@ -0,0 +1,107 @@
+package passwd
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/openwall/yescrypt-go"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+type CiscoType8 struct {
+	Passwd
+}
+
+type CiscoType9 struct {
+	Passwd
+}
+
+// Make a Cisco type 5 secret instance, which is MD5Crypt with a 4 character salt.
+func NewCiscoType5Passwd() PasswdInterface {
+	m := NewMD5CryptPasswd().(*MD5Crypt)
+	m.SaltLength = 4
+	return m
+}
+
+// Make a Cisco type 8 secret instance, which is PBKDF2 with SHA256.
+func NewCiscoType8Passwd() PasswdInterface {
+	m := new(CiscoType8)
+	m.Magic = CISCO_TYPE8_MAGIC
+	m.SaltLength = 14
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a Cisco type 9 secret instance, which is scrypt.
+func NewCiscoType9Passwd() PasswdInterface {
+	m := new(CiscoType9)
+	m.Magic = CISCO_TYPE9_MAGIC
+	m.SaltLength = 14
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Check the salt is 14 characters, which is used as is for the digest.
+func ciscoCheckSalt(salt []byte) error {
+	if len(salt) != 14 || !validIota64(salt) {
+		return errors.New("Cisco salt must be 14 characters")
+	}
+	return nil
+}
+
+// Generate a 14 character salt.
+func (a *CiscoType8) GenerateSalt() ([]byte, error) {
+	return generateIota64Salt(uint(a.SaltLength))
+}
+
+// Hash a password with salt using PBKDF2 with SHA256 and 20000 iterations.
+func (a *CiscoType8) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	err = ciscoCheckSalt(salt)
+	if err != nil {
+		return
+	}
+	key := pbkdf2.Key(password, salt, 20000, SHA256_SIZE, sha256.New)
+
+	// Create hash with result.
+	hash = append([]byte(a.Magic), salt...)
+	hash = append(hash, '$')
+	hash = append(hash, CiscoBase64Encode(key)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with Cisco type 8.
+func (a *CiscoType8) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// Generate a 14 character salt.
+func (a *CiscoType9) GenerateSalt() ([]byte, error) {
+	return generateIota64Salt(uint(a.SaltLength))
+}
+
+// Hash a password with salt using scrypt with N=16384, r=1, and p=1.
+func (a *CiscoType9) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	err = ciscoCheckSalt(salt)
+	if err != nil {
+		return
+	}
+	key, err := yescrypt.ScryptKey(password, salt, 16384, 1, 1, 32)
+	if err != nil {
+		return
+	}
+
+	// Create hash with result.
+	hash = append([]byte(a.Magic), salt...)
+	hash = append(hash, '$')
+	hash = append(hash, CiscoBase64Encode(key)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with Cisco type 9.
+func (a *CiscoType9) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
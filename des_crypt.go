@@ -0,0 +1,254 @@
+package passwd
+
+import (
+	"errors"
+)
+
+type DESCrypt struct {
+	Passwd
+}
+
+// Make a traditional DES crypt password instance.
+func NewDESCryptPasswd() PasswdInterface {
+	m := new(DESCrypt)
+	m.Magic = DES_CRYPT_MAGIC
+	// The salt is 2 characters, which is 12 bits.
+	m.SaltLength = 2
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Initial permutation of the 64-bit block, numbered from the most significant bit.
+var desInitialPermutation = [64]byte{
+	58, 50, 42, 34, 26, 18, 10, 2,
+	60, 52, 44, 36, 28, 20, 12, 4,
+	62, 54, 46, 38, 30, 22, 14, 6,
+	64, 56, 48, 40, 32, 24, 16, 8,
+	57, 49, 41, 33, 25, 17, 9, 1,
+	59, 51, 43, 35, 27, 19, 11, 3,
+	61, 53, 45, 37, 29, 21, 13, 5,
+	63, 55, 47, 39, 31, 23, 15, 7,
+}
+
+// Final permutation, the inverse of the initial permutation.
+var desFinalPermutation = [64]byte{
+	40, 8, 48, 16, 56, 24, 64, 32,
+	39, 7, 47, 15, 55, 23, 63, 31,
+	38, 6, 46, 14, 54, 22, 62, 30,
+	37, 5, 45, 13, 53, 21, 61, 29,
+	36, 4, 44, 12, 52, 20, 60, 28,
+	35, 3, 43, 11, 51, 19, 59, 27,
+	34, 2, 42, 10, 50, 18, 58, 26,
+	33, 1, 41, 9, 49, 17, 57, 25,
+}
+
+// Expansion of the 32-bit half block to 48 bits.
+var desExpansion = [48]byte{
+	32, 1, 2, 3, 4, 5,
+	4, 5, 6, 7, 8, 9,
+	8, 9, 10, 11, 12, 13,
+	12, 13, 14, 15, 16, 17,
+	16, 17, 18, 19, 20, 21,
+	20, 21, 22, 23, 24, 25,
+	24, 25, 26, 27, 28, 29,
+	28, 29, 30, 31, 32, 1,
+}
+
+// Permutation of the S-box output.
+var desPermutation = [32]byte{
+	16, 7, 20, 21, 29, 12, 28, 17,
+	1, 15, 23, 26, 5, 18, 31, 10,
+	2, 8, 24, 14, 32, 27, 3, 9,
+	19, 13, 30, 6, 22, 11, 4, 25,
+}
+
+// Selects 56 bits of the key, dropping the parity bits.
+var desPermutedChoice1 = [56]byte{
+	57, 49, 41, 33, 25, 17, 9,
+	1, 58, 50, 42, 34, 26, 18,
+	10, 2, 59, 51, 43, 35, 27,
+	19, 11, 3, 60, 52, 44, 36,
+	63, 55, 47, 39, 31, 23, 15,
+	7, 62, 54, 46, 38, 30, 22,
+	14, 6, 61, 53, 45, 37, 29,
+	21, 13, 5, 28, 20, 12, 4,
+}
+
+// Selects 48 bits of the rotated key for each round.
+var desPermutedChoice2 = [48]byte{
+	14, 17, 11, 24, 1, 5,
+	3, 28, 15, 6, 21, 10,
+	23, 19, 12, 4, 26, 8,
+	16, 7, 27, 20, 13, 2,
+	41, 52, 31, 37, 47, 55,
+	30, 40, 51, 45, 33, 48,
+	44, 49, 39, 56, 34, 53,
+	46, 42, 50, 36, 29, 32,
+}
+
+// Left rotations of the key halves for each round.
+var desKeyRotations = [16]uint{1, 1, 2, 2, 2, 2, 2, 2, 1, 2, 2, 2, 2, 2, 2, 1}
+
+// The 8 S-boxes, each with 4 rows and 16 columns.
+var desSBoxes = [8][4][16]byte{
+	{
+		{14, 4, 13, 1, 2, 15, 11, 8, 3, 10, 6, 12, 5, 9, 0, 7},
+		{0, 15, 7, 4, 14, 2, 13, 1, 10, 6, 12, 11, 9, 5, 3, 8},
+		{4, 1, 14, 8, 13, 6, 2, 11, 15, 12, 9, 7, 3, 10, 5, 0},
+		{15, 12, 8, 2, 4, 9, 1, 7, 5, 11, 3, 14, 10, 0, 6, 13},
+	},
+	{
+		{15, 1, 8, 14, 6, 11, 3, 4, 9, 7, 2, 13, 12, 0, 5, 10},
+		{3, 13, 4, 7, 15, 2, 8, 14, 12, 0, 1, 10, 6, 9, 11, 5},
+		{0, 14, 7, 11, 10, 4, 13, 1, 5, 8, 12, 6, 9, 3, 2, 15},
+		{13, 8, 10, 1, 3, 15, 4, 2, 11, 6, 7, 12, 0, 5, 14, 9},
+	},
+	{
+		{10, 0, 9, 14, 6, 3, 15, 5, 1, 13, 12, 7, 11, 4, 2, 8},
+		{13, 7, 0, 9, 3, 4, 6, 10, 2, 8, 5, 14, 12, 11, 15, 1},
+		{13, 6, 4, 9, 8, 15, 3, 0, 11, 1, 2, 12, 5, 10, 14, 7},
+		{1, 10, 13, 0, 6, 9, 8, 7, 4, 15, 14, 3, 11, 5, 2, 12},
+	},
+	{
+		{7, 13, 14, 3, 0, 6, 9, 10, 1, 2, 8, 5, 11, 12, 4, 15},
+		{13, 8, 11, 5, 6, 15, 0, 3, 4, 7, 2, 12, 1, 10, 14, 9},
+		{10, 6, 9, 0, 12, 11, 7, 13, 15, 1, 3, 14, 5, 2, 8, 4},
+		{3, 15, 0, 6, 10, 1, 13, 8, 9, 4, 5, 11, 12, 7, 2, 14},
+	},
+	{
+		{2, 12, 4, 1, 7, 10, 11, 6, 8, 5, 3, 15, 13, 0, 14, 9},
+		{14, 11, 2, 12, 4, 7, 13, 1, 5, 0, 15, 10, 3, 9, 8, 6},
+		{4, 2, 1, 11, 10, 13, 7, 8, 15, 9, 12, 5, 6, 3, 0, 14},
+		{11, 8, 12, 7, 1, 14, 2, 13, 6, 15, 0, 9, 10, 4, 5, 3},
+	},
+	{
+		{12, 1, 10, 15, 9, 2, 6, 8, 0, 13, 3, 4, 14, 7, 5, 11},
+		{10, 15, 4, 2, 7, 12, 9, 5, 6, 1, 13, 14, 0, 11, 3, 8},
+		{9, 14, 15, 5, 2, 8, 12, 3, 7, 0, 4, 10, 1, 13, 11, 6},
+		{4, 3, 2, 12, 9, 5, 15, 10, 11, 14, 1, 7, 6, 0, 8, 13},
+	},
+	{
+		{4, 11, 2, 14, 15, 0, 8, 13, 3, 12, 9, 7, 5, 10, 6, 1},
+		{13, 0, 11, 7, 4, 9, 1, 10, 14, 3, 5, 12, 2, 15, 8, 6},
+		{1, 4, 11, 13, 12, 3, 7, 14, 10, 15, 6, 8, 0, 5, 9, 2},
+		{6, 11, 13, 8, 1, 4, 10, 7, 9, 5, 0, 15, 14, 2, 3, 12},
+	},
+	{
+		{13, 2, 8, 4, 6, 15, 11, 1, 10, 9, 3, 14, 5, 0, 12, 7},
+		{1, 15, 13, 8, 10, 3, 7, 4, 12, 5, 6, 11, 0, 14, 9, 2},
+		{7, 11, 4, 1, 9, 12, 14, 2, 0, 6, 10, 13, 15, 3, 5, 8},
+		{2, 1, 14, 7, 4, 10, 8, 13, 15, 12, 9, 0, 3, 5, 6, 11},
+	},
+}
+
+// Permute the bits of src using a table numbered from the most significant bit.
+func desPermute(src uint64, srcBits uint, table []byte) (dst uint64) {
+	for _, b := range table {
+		dst = dst<<1 | (src>>(srcBits-uint(b)))&1
+	}
+	return
+}
+
+// Compute the 16 round keys for a 64-bit key.
+func desKeySchedule(key uint64) (ks [16]uint64) {
+	cd := desPermute(key, 64, desPermutedChoice1[:])
+	c := uint32(cd >> 28)
+	d := uint32(cd & 0x0fffffff)
+	for i, r := range desKeyRotations {
+		c = (c<<r | c>>(28-r)) & 0x0fffffff
+		d = (d<<r | d>>(28-r)) & 0x0fffffff
+		ks[i] = desPermute(uint64(c)<<28|uint64(d), 56, desPermutedChoice2[:])
+	}
+	return
+}
+
+// Convert a salt to the mask of expansion bits it swaps. Bit i of the salt
+// swaps bit i of the expansion output with bit i+24, counting from the most
+// significant bit. This makes the hash incompatible with DES hardware.
+func desSaltBits(salt uint32) (saltBits uint64) {
+	for i := uint(0); i < 24; i++ {
+		if salt&(1<<i) != 0 {
+			saltBits |= 1 << (23 - i)
+		}
+	}
+	return
+}
+
+// Encrypt a block count times with the salt modified DES.
+func desCrypt(ks *[16]uint64, block uint64, saltBits uint64, count uint32) uint64 {
+	for ; count > 0; count-- {
+		block = desPermute(block, 64, desInitialPermutation[:])
+		l := uint32(block >> 32)
+		r := uint32(block)
+		for i := 0; i < 16; i++ {
+			// Expand the right half and swap bits as defined by the salt.
+			e := desPermute(uint64(r), 32, desExpansion[:])
+			f := ((e >> 24) ^ e) & saltBits
+			e ^= f<<24 | f
+			e ^= ks[i]
+
+			// Substitute each 6 bits with the S-boxes.
+			var s uint64
+			for j := 0; j < 8; j++ {
+				b := (e >> (42 - 6*j)) & 0x3f
+				row := (b>>4)&2 | b&1
+				col := (b >> 1) & 0xf
+				s = s<<4 | uint64(desSBoxes[j][row][col])
+			}
+			p := uint32(desPermute(s, 32, desPermutation[:]))
+			l, r = r, l^p
+		}
+		block = desPermute(uint64(r)<<32|uint64(l), 64, desFinalPermutation[:])
+	}
+	return block
+}
+
+// Build a DES key from up to 8 characters of the password.
+// Only the low 7 bits of each character are used.
+func desKeyFromPassword(password []byte) (key uint64) {
+	for i := 0; i < 8; i++ {
+		key <<= 8
+		if i < len(password) {
+			key |= uint64(password[i] << 1)
+		}
+	}
+	return
+}
+
+// Decode the 12-bit salt from 2 characters.
+func desDecodeSalt(salt []byte) (uint32, error) {
+	if len(salt) < 2 || !validIota64(salt[:2]) {
+		return 0, errors.New("DES crypt salt must be 2 characters")
+	}
+	return uint32(AToI64(salt[1]))<<6 | uint32(AToI64(salt[0])), nil
+}
+
+// Generate a 2 character salt.
+func (a *DESCrypt) GenerateSalt() ([]byte, error) {
+	return generateIota64Salt(uint(a.SaltLength))
+}
+
+// Hash a password with salt using the traditional DES crypt standard.
+func (a *DESCrypt) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	saltVal, err := desDecodeSalt(salt)
+	if err != nil {
+		return
+	}
+
+	// Passwords are truncated to 8 characters, and 25 iterations
+	// of DES are encrypted on an empty block.
+	ks := desKeySchedule(desKeyFromPassword(password))
+	block := desCrypt(&ks, 0, desSaltBits(saltVal), 25)
+
+	// Create hash with result.
+	hash = append([]byte(a.Magic), salt[:2]...)
+	hash = append(hash, DESBase64Encode(block)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with DES crypt.
+func (a *DESCrypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
@@ -0,0 +1,272 @@
+package passwd
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+
+	"github.com/openwall/yescrypt-go"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+type DjangoPBKDF2 struct {
+	Passwd
+}
+
+type DjangoSCrypt struct {
+	Passwd
+}
+
+type DjangoHash struct {
+	Passwd
+}
+
+// Django bcrypt_sha256 is bcrypt of the hex encoded SHA256 of the password.
+type DjangoBcryptSHA256 struct {
+	PrefixPasswd
+}
+
+// Make a Django pbkdf2_sha256 password instance.
+func NewDjangoPBKDF2SHA256Passwd() PasswdInterface {
+	return newDjangoPBKDF2Passwd(DJANGO_PBKDF2_SHA256_MAGIC)
+}
+
+// Make a Django pbkdf2_sha1 password instance.
+func NewDjangoPBKDF2SHA1Passwd() PasswdInterface {
+	return newDjangoPBKDF2Passwd(DJANGO_PBKDF2_SHA1_MAGIC)
+}
+
+// Make a Django PBKDF2 password instance for the magic.
+func newDjangoPBKDF2Passwd(magic string) *DjangoPBKDF2 {
+	m := new(DjangoPBKDF2)
+	m.Magic = magic
+	m.Params = "600000"
+	m.SaltLength = 22
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a Django argon2 password instance, which prefixes an Argon2id hash.
+func NewDjangoArgon2Passwd() PasswdInterface {
+	crypt := newArgon2Passwd(ARGON2ID_MAGIC)
+	crypt.SetArgon2Params(102400, 2, 8)
+	return NewPrefixPasswd(DJANGO_ARGON2_MAGIC, crypt)
+}
+
+// Make a Django bcrypt password instance, which prefixes a bcrypt hash.
+func NewDjangoBcryptPasswd() PasswdInterface {
+	crypt := NewBcryptPasswd().(*Bcrypt)
+	crypt.SetCost(12)
+	return NewPrefixPasswd(DJANGO_BCRYPT_MAGIC, crypt)
+}
+
+// Make a Django bcrypt_sha256 password instance.
+func NewDjangoBcryptSHA256Passwd() PasswdInterface {
+	crypt := NewBcryptPasswd().(*Bcrypt)
+	crypt.SetCost(12)
+	return NewDjangoBcryptSHA256WrapPasswd(crypt)
+}
+
+// Make a Django bcrypt_sha256 password instance, which wraps a bcrypt password instance.
+func NewDjangoBcryptSHA256WrapPasswd(crypt PasswdInterface) PasswdInterface {
+	return &DjangoBcryptSHA256{PrefixPasswd{PasswdInterface: crypt, Magic: DJANGO_BCRYPT_SHA256_MAGIC}}
+}
+
+// Make a Django scrypt password instance.
+func NewDjangoSCryptPasswd() PasswdInterface {
+	m := new(DjangoSCrypt)
+	m.Magic = DJANGO_SCRYPT_MAGIC
+	m.SetSCryptParams(16384, 8, 1)
+	m.SaltLength = 22
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a Django salted MD5 password instance.
+func NewDjangoMD5Passwd() PasswdInterface {
+	return newDjangoHashPasswd(DJANGO_MD5_MAGIC, 22)
+}
+
+// Make a Django salted SHA1 password instance.
+func NewDjangoSHA1Passwd() PasswdInterface {
+	return newDjangoHashPasswd(DJANGO_SHA1_MAGIC, 22)
+}
+
+// Make a Django unsalted MD5 password instance, in the md5$$<hash> format.
+func NewDjangoUnsaltedMD5Passwd() PasswdInterface {
+	return newDjangoHashPasswd(DJANGO_MD5_MAGIC, -1)
+}
+
+// Make a Django unsalted SHA1 password instance, in the sha1$$<hash> format.
+func NewDjangoUnsaltedSHA1Passwd() PasswdInterface {
+	return newDjangoHashPasswd(DJANGO_SHA1_MAGIC, -1)
+}
+
+// Make a Django digest password instance for the magic.
+func newDjangoHashPasswd(magic string, saltLength int) *DjangoHash {
+	m := new(DjangoHash)
+	m.Magic = magic
+	m.SaltLength = saltLength
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a Django crypt password instance, which prefixes a DES crypt hash.
+func NewDjangoCryptPasswd() PasswdInterface {
+	return NewPrefixPasswd(DJANGO_CRYPT_MAGIC, NewDESCryptPasswd())
+}
+
+// Get the hash function for the magic.
+func (a *DjangoPBKDF2) hashFunc() (h func() hash.Hash, size int, err error) {
+	switch a.Magic {
+	case DJANGO_PBKDF2_SHA256_MAGIC:
+		h, size = sha256.New, SHA256_SIZE
+	case DJANGO_PBKDF2_SHA1_MAGIC:
+		h, size = sha1.New, SHA1_SIZE
+	default:
+		err = errors.New("unsupported Django PBKDF2 hash")
+	}
+	return
+}
+
+// Generate an alphanumeric salt.
+func (a *DjangoPBKDF2) GenerateSalt() ([]byte, error) {
+	return generateAlnumSalt(a.SaltLength)
+}
+
+// Hash a password with salt using PBKDF2 in the Django format.
+func (a *DjangoPBKDF2) Hash(password []byte, salt []byte, iterations int) (hash []byte, err error) {
+	h, size, err := a.hashFunc()
+	if err != nil {
+		return
+	}
+	if iterations < 1 {
+		err = errors.New("PBKDF2 iterations must be greater than 0")
+		return
+	}
+	key := pbkdf2.Key(password, salt, iterations, size, h)
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%d$%s$%s", a.Magic, iterations, salt, base64.StdEncoding.EncodeToString(key)))
+	return
+}
+
+// Override the passwd hash with salt function to hash with Django's PBKDF2.
+func (a *DjangoPBKDF2) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	iterations, err := strconv.Atoi(a.Params)
+	if err != nil {
+		return
+	}
+
+	hash, err = a.Hash(password, salt, iterations)
+	return
+}
+
+// Sets the scrypt params, N is the work factor.
+func (a *DjangoSCrypt) SetSCryptParams(N, r, p int) {
+	a.Params = fmt.Sprintf("n=%d,r=%d,p=%d", N, r, p)
+}
+
+// Decode scrypt params.
+func (a *DjangoSCrypt) DecodeSCryptParams() (N, r, p int, err error) {
+	_, err = fmt.Sscanf(a.Params, "n=%d,r=%d,p=%d", &N, &r, &p)
+	return
+}
+
+// Generate an alphanumeric salt.
+func (a *DjangoSCrypt) GenerateSalt() ([]byte, error) {
+	return generateAlnumSalt(a.SaltLength)
+}
+
+// Hash a password with salt using scrypt in the Django format.
+func (a *DjangoSCrypt) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	N, r, p, err := a.DecodeSCryptParams()
+	if err != nil {
+		return
+	}
+	key, err := yescrypt.ScryptKey(password, salt, N, r, p, 64)
+	if err != nil {
+		return
+	}
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%d$%s$%d$%d$%s", a.Magic, N, salt, r, p, base64.StdEncoding.EncodeToString(key)))
+	return
+}
+
+// Override the passwd hash with salt function to hash with Django's scrypt.
+func (a *DjangoSCrypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// Generate an alphanumeric salt, or no salt for the unsalted hashes.
+func (a *DjangoHash) GenerateSalt() ([]byte, error) {
+	if a.SaltLength < 0 {
+		return nil, nil
+	}
+	return generateAlnumSalt(a.SaltLength)
+}
+
+// Get the hash function for the magic.
+func (a *DjangoHash) hashFunc() (h hash.Hash, err error) {
+	switch a.Magic {
+	case DJANGO_MD5_MAGIC:
+		h = md5.New()
+	case DJANGO_SHA1_MAGIC:
+		h = sha1.New()
+	default:
+		err = errors.New("unsupported Django digest")
+	}
+	return
+}
+
+// Hash a password with salt using the digest of the salt and password.
+func (a *DjangoHash) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	h, err := a.hashFunc()
+	if err != nil {
+		return
+	}
+	h.Write(salt)
+	h.Write(password)
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%s$%s", a.Magic, salt, hex.EncodeToString(h.Sum(nil))))
+	return
+}
+
+// Override the passwd hash with salt function to hash with Django's digest.
+func (a *DjangoHash) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// Pre-hash the password to the hex encoded SHA256, avoiding the bcrypt length limit.
+func (a *DjangoBcryptSHA256) prehash(password []byte) []byte {
+	sum := sha256.Sum256(password)
+	return []byte(hex.EncodeToString(sum[:]))
+}
+
+// Hash a pre-hashed password with bcrypt, and prefix the magic.
+func (a *DjangoBcryptSHA256) HashPassword(password []byte) (hash []byte, err error) {
+	return a.PrefixPasswd.HashPassword(a.prehash(password))
+}
+
+// Hash a pre-hashed password and salt with bcrypt, and prefix the magic.
+func (a *DjangoBcryptSHA256) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	return a.PrefixPasswd.HashPasswordWithSalt(a.prehash(password), salt)
+}
+
+// Check a pre-hashed password against the bcrypt hash.
+func (a *DjangoBcryptSHA256) CheckPassword(hash []byte, password []byte) (bool, error) {
+	return a.PrefixPasswd.CheckPassword(hash, a.prehash(password))
+}
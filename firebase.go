@@ -0,0 +1,123 @@
+package passwd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/openwall/yescrypt-go"
+)
+
+// Firebase Authentication's modified scrypt, where the scrypt derived key is
+// used to encrypt the project's signer key with AES-256-CTR. The salt is not
+// stored in the hash, so it must be set with SetSalt before checking a hash.
+type FirebaseScrypt struct {
+	Passwd
+	SignerKey     []byte
+	SaltSeparator []byte
+}
+
+// Make a Firebase scrypt password instance with the project's hash config, as
+// shown in the console with the signer key and salt separator in base64.
+func NewFirebaseScryptPasswd(signerKey, saltSeparator string, rounds, memCost int) (PasswdInterface, error) {
+	key, err := base64.StdEncoding.DecodeString(signerKey)
+	if err != nil {
+		return nil, err
+	}
+	separator, err := base64.StdEncoding.DecodeString(saltSeparator)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(FirebaseScrypt)
+	m.SignerKey = key
+	m.SaltSeparator = separator
+	m.SetScryptParams(rounds, memCost)
+	m.SaltLength = 12
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m, nil
+}
+
+// Sets the scrypt params, rounds is r and mem cost is the N log 2.
+func (a *FirebaseScrypt) SetScryptParams(rounds, memCost int) {
+	a.Params = fmt.Sprintf("rounds=%d,mem_cost=%d", rounds, memCost)
+}
+
+// Decode scrypt params.
+func (a *FirebaseScrypt) DecodeScryptParams() (rounds, memCost int, err error) {
+	_, err = fmt.Sscanf(a.Params, "rounds=%d,mem_cost=%d", &rounds, &memCost)
+	return
+}
+
+// Generate a salt encoded with standard base64, as in Firebase user exports.
+func (a *FirebaseScrypt) GenerateSalt() ([]byte, error) {
+	rawSalt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, base64.StdEncoding.EncodedLen(len(rawSalt)))
+	base64.StdEncoding.Encode(salt, rawSalt)
+	return salt, nil
+}
+
+// Hash a password with the base64 salt using Firebase's modified scrypt.
+func (a *FirebaseScrypt) Hash(password []byte, salt []byte, rounds, memCost int) (hash []byte, err error) {
+	if len(a.SignerKey) == 0 {
+		err = errors.New("Firebase scrypt requires the signer key")
+		return
+	}
+	if memCost < 1 || memCost > 31 {
+		err = errors.New("Firebase scrypt mem cost must be between 1 and 31")
+		return
+	}
+	rawSalt, err := base64.StdEncoding.DecodeString(string(salt))
+	if err != nil {
+		return
+	}
+
+	// The salt separator is appended to the salt.
+	rawSalt = append(rawSalt, a.SaltSeparator...)
+	key, err := yescrypt.ScryptKey(password, rawSalt, 1<<memCost, rounds, 1, 32)
+	if err != nil {
+		return
+	}
+
+	// Encrypt the signer key with the derived key, using a zero IV.
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+	buf := make([]byte, len(a.SignerKey))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(buf, a.SignerKey)
+
+	hash = make([]byte, base64.StdEncoding.EncodedLen(len(buf)))
+	base64.StdEncoding.Encode(hash, buf)
+	return
+}
+
+// Override the passwd hash with salt function to hash with Firebase's scrypt.
+func (a *FirebaseScrypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	rounds, memCost, err := a.DecodeScryptParams()
+	if err != nil {
+		return
+	}
+
+	hash, err = a.Hash(password, salt, rounds, memCost)
+	return
+}
+
+// Check an exported password hash, using the salt set with SetSalt.
+func (a *FirebaseScrypt) CheckPassword(hash []byte, password []byte) (bool, error) {
+	if len(a.Salt) == 0 {
+		return false, errors.New("Firebase scrypt requires the user's salt")
+	}
+	newHash, err := a.HashPasswordWithSalt(password, a.Salt)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(hash, newHash), nil
+}
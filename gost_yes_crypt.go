@@ -4,12 +4,12 @@ import (
 	"crypto/hmac"
 	"fmt"
 
-	"github.com/openwall/yescrypt-go"
 	"github.com/pedroalbanese/gogost/gost34112012256"
 )
 
 type GostYesCrypt struct {
 	Passwd
+	ROM *YescryptROM
 }
 
 // Make an MD5Crypt password instance.
@@ -23,39 +23,62 @@ func NewGostYesCryptPasswd() PasswdInterface {
 	return m
 }
 
-// Sets the SCrypt params using integers.
+// Sets the SCrypt params using integers, which are the base 64 values of
+// the N log 2 minus 1 and r minus 1 as stored in the short params format.
 func (a *GostYesCrypt) SetSCryptParams(N, r int) (err error) {
-	Nval, err := IToA64(N)
+	params := DefaultYescryptParams()
+	params.N = 1 << uint(N+1)
+	params.R = uint32(r + 1)
+	return a.SetYescryptParams(params)
+}
+
+// Decode SCrypt params.
+func (a *GostYesCrypt) DecodeSCriptParams() (N, r int) {
+	params, err := a.DecodeYescryptParams()
 	if err != nil {
 		return
 	}
-	rval, err := IToA64(r)
+	N = N2log2(params.N) - 1
+	r = int(params.R) - 1
+	return
+}
+
+// Sets the full yescrypt params.
+func (a *GostYesCrypt) SetYescryptParams(params YescryptParams) (err error) {
+	p, err := params.Encode()
 	if err != nil {
 		return
 	}
-	a.Params = fmt.Sprintf("j%c%c", Nval, rval)
+	a.Params = p
 	return
 }
 
-// Decode SCrypt params.
-func (a *GostYesCrypt) DecodeSCriptParams() (N, r int) {
-	b64 := []byte(a.Params)
-	if len(b64) != 3 {
-		return
+// Sets the ROM used for hashes with params referencing one.
+func (a *GostYesCrypt) SetROM(rom *YescryptROM) {
+	a.ROM = rom
+}
+
+// Get the ROM set, or the default ROM if none is set.
+func (a *GostYesCrypt) yescryptROM() *YescryptROM {
+	if a.ROM != nil {
+		return a.ROM
 	}
-	N = AToI64(b64[1])
-	r = AToI64(b64[2])
-	return
+	return DefaultYescryptROM()
+}
+
+// Decode the full yescrypt params.
+func (a *GostYesCrypt) DecodeYescryptParams() (YescryptParams, error) {
+	return DecodeYescryptParams(a.Params)
 }
 
 // Hash a password with salt using gost yes crypt standard.
 func (a *GostYesCrypt) Hash(password []byte, salt []byte) (hash []byte, err error) {
 	output := []byte(fmt.Sprintf("%s%s$%s", YES_CRYPT_MAGIC, a.Params, salt))
-	yescryptHash, err := yescrypt.Hash(password, output)
+	yesHash, err := yescryptHash(password, output, a.yescryptROM())
 	if err != nil {
 		return
 	}
-	bytes := SCryptBase64Decode(yescryptHash[len(output)+1:])
+	bytes := SCryptBase64Decode(yesHash[len(output)+1:])
 
 	h := gost34112012256.New()
 	h.Write(password)
@@ -0,0 +1,231 @@
+package passwd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Kerberos encryption types supported for string-to-key.
+const (
+	KERBEROS_AES128_CTS_HMAC_SHA1_96 = 17
+	KERBEROS_AES256_CTS_HMAC_SHA1_96 = 18
+	KERBEROS_RC4_HMAC                = 23
+)
+
+// Kerberos principal name types.
+const (
+	KERBEROS_NT_PRINCIPAL = 1
+	KERBEROS_NT_SRV_HST   = 3
+)
+
+// The default PBKDF2 iterations for the AES encryption types.
+const kerberosAESIterations = 4096
+
+// A Kerberos principal, such as host/www.example.com@EXAMPLE.COM.
+type KerberosPrincipal struct {
+	Components []string
+	Realm      string
+	NameType   uint32
+}
+
+// A Kerberos key for an encryption type.
+type KerberosKey struct {
+	EncType uint16
+	Key     []byte
+}
+
+// Parse a principal in the <component>[/<component>...]@<realm> format.
+func ParseKerberosPrincipal(principal string) (p KerberosPrincipal, err error) {
+	i := strings.LastIndex(principal, "@")
+	if i < 1 || i == len(principal)-1 {
+		err = errors.New("Kerberos principal must include the realm")
+		return
+	}
+	p.Realm = principal[i+1:]
+	p.Components = strings.Split(principal[:i], "/")
+	p.NameType = KERBEROS_NT_PRINCIPAL
+	return
+}
+
+// Format the principal.
+func (p KerberosPrincipal) String() string {
+	return strings.Join(p.Components, "/") + "@" + p.Realm
+}
+
+// Get the default salt, which is the realm followed by the components.
+func (p KerberosPrincipal) Salt() []byte {
+	return []byte(p.Realm + strings.Join(p.Components, ""))
+}
+
+// Fold the input to n bytes, as defined in RFC 3961.
+func kerberosNFold(in []byte, n int) []byte {
+	inLen := len(in)
+	lcm := n
+	for a, b := inLen, n; b != 0; {
+		a, b = b, a%b
+		if b == 0 {
+			lcm = inLen * n / a
+		}
+	}
+
+	// The input is repeated with a 13 bit rotation each time, and the
+	// copies are added together in n byte chunks with ones' complement.
+	out := make([]byte, n)
+	carry := 0
+	for i := lcm - 1; i >= 0; i-- {
+		msbit := ((inLen << 3) - 1 + ((inLen<<3)+13)*(i/inLen) + ((inLen - (i % inLen)) << 3)) % (inLen << 3)
+		b := (int(in[((inLen-1)-(msbit>>3))%inLen])<<8 | int(in[(inLen-(msbit>>3))%inLen])) >> ((msbit & 7) + 1) & 0xff
+		b += carry + int(out[i%n])
+		out[i%n] = byte(b)
+		carry = b >> 8
+	}
+	for i := n - 1; i >= 0 && carry != 0; i-- {
+		b := int(out[i]) + carry
+		out[i] = byte(b)
+		carry = b >> 8
+	}
+	return out
+}
+
+// Derive a key with the AES encryption of the folded constant, as defined in RFC 3961.
+func kerberosAESDeriveKey(key, constant []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	buf := kerberosNFold(constant, aes.BlockSize)
+	var derived []byte
+	for len(derived) < len(key) {
+		block.Encrypt(buf, buf)
+		derived = append(derived, buf...)
+	}
+	return derived[:len(key)], nil
+}
+
+// Convert a password to a key for the encryption type. The AES types use
+// PBKDF2 with the salt and iterations, where 0 iterations uses the default.
+// The RC4 type is the NT hash, which ignores the salt.
+func KerberosStringToKey(encType uint16, password, salt []byte, iterations int) (key []byte, err error) {
+	switch encType {
+	case KERBEROS_AES128_CTS_HMAC_SHA1_96, KERBEROS_AES256_CTS_HMAC_SHA1_96:
+		size := 16
+		if encType == KERBEROS_AES256_CTS_HMAC_SHA1_96 {
+			size = 32
+		}
+		if iterations == 0 {
+			iterations = kerberosAESIterations
+		}
+		if iterations < 1 {
+			err = errors.New("Kerberos iterations must be greater than 0")
+			return
+		}
+		tkey := pbkdf2.Key(password, salt, iterations, size, sha1.New)
+		key, err = kerberosAESDeriveKey(tkey, []byte("kerberos"))
+	case KERBEROS_RC4_HMAC:
+		nt := NewNTPasswd().(*NTHash)
+		hash := nt.Hash(password)
+		key, err = hex.DecodeString(string(hash[len(NT_HASH_MAGIC)+1:]))
+	default:
+		err = errors.New("unsupported Kerberos encryption type")
+	}
+	return
+}
+
+// Convert a password to keys for a principal, using its default salt.
+func KerberosPasswordKeys(principal KerberosPrincipal, password []byte, encTypes ...uint16) (keys []KerberosKey, err error) {
+	for _, encType := range encTypes {
+		key, err := KerberosStringToKey(encType, password, principal.Salt(), 0)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, KerberosKey{EncType: encType, Key: key})
+	}
+	return
+}
+
+// An entry in a keytab.
+type KerberosKeytabEntry struct {
+	Principal KerberosPrincipal
+	Timestamp time.Time
+	KVNO      uint32
+	Key       KerberosKey
+}
+
+// A keytab, which is written in the MIT version 2 format.
+type KerberosKeytab struct {
+	Entries []KerberosKeytabEntry
+}
+
+// Add keys derived from a password for the principal to the keytab.
+func (k *KerberosKeytab) AddPassword(principal KerberosPrincipal, password []byte, kvno uint32, encTypes ...uint16) error {
+	keys, err := KerberosPasswordKeys(principal, password, encTypes...)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, key := range keys {
+		k.Entries = append(k.Entries, KerberosKeytabEntry{Principal: principal, Timestamp: now, KVNO: kvno, Key: key})
+	}
+	return nil
+}
+
+// Append a string with its 16 bit length.
+func kerberosAppendString(buf []byte, s []byte) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+// Encode the keytab in the MIT format.
+func (k *KerberosKeytab) MarshalBinary() ([]byte, error) {
+	buf := []byte{0x05, 0x02}
+	for _, e := range k.Entries {
+		if len(e.Principal.Components) == 0 || len(e.Principal.Components) > 0xffff {
+			return nil, errors.New("Kerberos principal has an invalid number of components")
+		}
+		entry := binary.BigEndian.AppendUint16(nil, uint16(len(e.Principal.Components)))
+		entry = kerberosAppendString(entry, []byte(e.Principal.Realm))
+		for _, c := range e.Principal.Components {
+			entry = kerberosAppendString(entry, []byte(c))
+		}
+		entry = binary.BigEndian.AppendUint32(entry, e.Principal.NameType)
+		entry = binary.BigEndian.AppendUint32(entry, uint32(e.Timestamp.Unix()))
+
+		// The 8 bit key version is followed by the full 32 bit key version.
+		entry = append(entry, byte(e.KVNO))
+		entry = binary.BigEndian.AppendUint16(entry, e.Key.EncType)
+		entry = kerberosAppendString(entry, e.Key.Key)
+		entry = binary.BigEndian.AppendUint32(entry, e.KVNO)
+
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(entry)))
+		buf = append(buf, entry...)
+	}
+	return buf, nil
+}
+
+// Write the keytab in the MIT format.
+func (k *KerberosKeytab) WriteTo(w io.Writer) (n int64, err error) {
+	buf, err := k.MarshalBinary()
+	if err != nil {
+		return
+	}
+	return bytes.NewReader(buf).WriteTo(w)
+}
+
+// Write the keytab to a file, which is only readable by the owner.
+func (k *KerberosKeytab) WriteFile(path string) error {
+	buf, err := k.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0600)
+}
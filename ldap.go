@@ -0,0 +1,114 @@
+package passwd
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"strings"
+)
+
+type LDAPHash struct {
+	Passwd
+}
+
+type LDAPCrypt struct {
+	PrefixPasswd
+}
+
+// RFC 2307 style schemes, and if they are salted.
+var ldapSchemes = map[string]struct {
+	h      func() hash.Hash
+	salted bool
+}{
+	"SHA":     {sha1.New, false},
+	"SSHA":    {sha1.New, true},
+	"SHA256":  {sha256.New, false},
+	"SSHA256": {sha256.New, true},
+	"SHA512":  {sha512.New, false},
+	"SSHA512": {sha512.New, true},
+	"MD5":     {md5.New, false},
+	"SMD5":    {md5.New, true},
+}
+
+// Make an LDAP userPassword instance for a scheme such as SSHA.
+func NewLDAPPasswd(scheme string) (PasswdInterface, error) {
+	s, ok := ldapSchemes[strings.ToUpper(scheme)]
+	if !ok {
+		return nil, errors.New("unsupported LDAP password scheme")
+	}
+
+	m := new(LDAPHash)
+	m.Magic = "{" + scheme + "}"
+	// The salt is raw bytes appended to the digest.
+	m.SaltLength = 8
+	if !s.salted {
+		m.SaltLength = -1
+	}
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m, nil
+}
+
+// Make an LDAP {CRYPT} instance, which wraps a crypt password instance.
+func NewLDAPCryptPasswd(crypt PasswdInterface) PasswdInterface {
+	return &LDAPCrypt{PrefixPasswd{PasswdInterface: crypt, Magic: LDAP_CRYPT_MAGIC}}
+}
+
+// Split an LDAP userPassword value into the scheme and value.
+func parseLDAPScheme(settings string) (scheme string, value string, ok bool) {
+	if !strings.HasPrefix(settings, "{") {
+		return
+	}
+	end := strings.Index(settings, "}")
+	if end < 2 {
+		return
+	}
+	return settings[1:end], settings[end+1:], true
+}
+
+// Get the scheme of this instance.
+func (a *LDAPHash) scheme() string {
+	return strings.ToUpper(strings.Trim(a.Magic, "{}"))
+}
+
+// Generate a raw salt, as it is encoded with the digest.
+func (a *LDAPHash) GenerateSalt() ([]byte, error) {
+	if a.SaltLength < 0 {
+		return nil, nil
+	}
+	return generateRandomBytes(uint(a.SaltLength))
+}
+
+// Hash a password with salt using the LDAP scheme.
+func (a *LDAPHash) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	s, ok := ldapSchemes[a.scheme()]
+	if !ok {
+		err = errors.New("unsupported LDAP password scheme")
+		return
+	}
+	if !s.salted {
+		salt = nil
+	}
+
+	// Salted schemes append the salt to both the password and digest.
+	h := s.h()
+	h.Write(password)
+	h.Write(salt)
+	buf := h.Sum(nil)
+	buf = append(buf, salt...)
+
+	// Create hash with result.
+	hash = []byte(a.Magic)
+	hash = append(hash, base64.StdEncoding.EncodeToString(buf)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with the LDAP scheme.
+func (a *LDAPHash) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
@@ -0,0 +1,67 @@
+package passwd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+type LMHash struct {
+	Passwd
+}
+
+// The constant encrypted with each half of the password.
+const lmMagicText = "KGS!@#$%"
+
+// Make a LAN Manager password instance.
+func NewLMPasswd() PasswdInterface {
+	m := new(LMHash)
+	m.Magic = LM_HASH_MAGIC
+	// LM hashes have no salt, so we disable it.
+	m.SaltLength = -1
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Spread 7 bytes of the password over a 64-bit DES key, skipping the parity bits.
+func lmKey(half []byte) (key uint64) {
+	var bits uint64
+	for _, c := range half {
+		bits = bits<<8 | uint64(c)
+	}
+	for i := 0; i < 8; i++ {
+		key = key<<8 | ((bits>>(49-7*uint(i)))&0x7f)<<1
+	}
+	return
+}
+
+// Hash a LAN Manager hash, which is uppercase hex like in smbpasswd files.
+func (a *LMHash) Hash(password []byte) (hash []byte) {
+	// The password is uppercased, and padded or truncated to 14 characters.
+	pw := make([]byte, 14)
+	copy(pw, bytes.ToUpper(password))
+
+	// Each half is a DES key, which encrypts the magic text.
+	block := binary.BigEndian.Uint64([]byte(lmMagicText))
+	buf := make([]byte, 16)
+	for i := 0; i < 2; i++ {
+		ks := desKeySchedule(lmKey(pw[i*7 : i*7+7]))
+		binary.BigEndian.PutUint64(buf[i*8:], desCrypt(&ks, block, 0, 1))
+	}
+
+	// Create hash with result.
+	hash = append([]byte(a.Magic), bytes.ToUpper([]byte(hex.EncodeToString(buf)))...)
+	return
+}
+
+// Override the hash with salt function with one that encodes the LM hash, ignoring the salt.
+func (a *LMHash) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash = a.Hash(password)
+	return
+}
+
+// Check a password against an LM hash, the hex may be in either case.
+func (a *LMHash) CheckPassword(hash []byte, password []byte) (bool, error) {
+	return bytes.EqualFold(hash, a.Hash(password)), nil
+}
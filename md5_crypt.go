@@ -4,6 +4,8 @@ import "crypto/md5"
 
 type MD5Crypt struct {
 	Passwd
+	// Written before the magic in the hash output, but not included in the digest.
+	Prefix string
 }
 
 // Make an MD5Crypt password instance.
@@ -17,6 +19,26 @@ func NewMD5CryptPasswd() PasswdInterface {
 	return m
 }
 
+// Make an Apache MD5 password instance, which only differs by the magic.
+func NewAPR1Passwd() PasswdInterface {
+	return NewMD5CryptMagicPasswd(APR1_CRYPT_MAGIC)
+}
+
+// Make an AIX MD5 password instance. AIX excludes the magic from the digest,
+// and identifies the hash with a scheme prefix instead.
+func NewAIXMD5Passwd() PasswdInterface {
+	m := NewMD5CryptMagicPasswd("").(*MD5Crypt)
+	m.Prefix = AIX_SMD5_MAGIC
+	return m
+}
+
+// Make an MD5Crypt password instance with a custom magic.
+func NewMD5CryptMagicPasswd(magic string) PasswdInterface {
+	m := NewMD5CryptPasswd().(*MD5Crypt)
+	m.Magic = magic
+	return m
+}
+
 // Hash a password with salt using MD5 crypt standard.
 func (a *MD5Crypt) Hash(password []byte, salt []byte) (hash []byte) {
 	magic := []byte(a.Magic)
@@ -97,7 +119,8 @@ func (a *MD5Crypt) Hash(password []byte, salt []byte) (hash []byte) {
 
 	// Create hash with result.
 	b64 := MD5Base64Encode(result)
-	hash = append(magic, salt...)
+	hash = append([]byte(a.Prefix), magic...)
+	hash = append(hash, salt...)
 	hash = append(hash, '$')
 	hash = append(hash, b64...)
 	return
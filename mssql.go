@@ -0,0 +1,105 @@
+package passwd
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strings"
+)
+
+type MSSQL struct {
+	Passwd
+}
+
+// Make a Microsoft SQL Server 2005 to 2008 password instance, which is SHA1.
+func NewMSSQL2005Passwd() PasswdInterface {
+	return newMSSQLPasswd(MSSQL_2005_MAGIC)
+}
+
+// Make a Microsoft SQL Server 2012 and later password instance, which is SHA512.
+func NewMSSQL2012Passwd() PasswdInterface {
+	return newMSSQLPasswd(MSSQL_2012_MAGIC)
+}
+
+// Make a Microsoft SQL Server password instance for the magic.
+func newMSSQLPasswd(magic string) *MSSQL {
+	m := new(MSSQL)
+	m.Magic = magic
+	// The salt is 4 raw bytes, encoded to 8 hex characters.
+	m.SaltLength = 4
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Get the hash function for the magic.
+func (a *MSSQL) hashFunc() (h func() hash.Hash, err error) {
+	switch a.Magic {
+	case MSSQL_2005_MAGIC:
+		h = sha1.New
+	case MSSQL_2012_MAGIC:
+		h = sha512.New
+	default:
+		err = errors.New("unsupported SQL Server hash version")
+	}
+	return
+}
+
+// Generate a salt encoded with upper case hex.
+func (a *MSSQL) GenerateSalt() ([]byte, error) {
+	rawSalt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.ToUpper(hex.EncodeToString(rawSalt))), nil
+}
+
+// Hash a password with the hex salt as done by SQL Server's PWDENCRYPT.
+func (a *MSSQL) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	h, err := a.hashFunc()
+	if err != nil {
+		return
+	}
+	rawSalt, err := hex.DecodeString(string(salt))
+	if err != nil {
+		return
+	}
+	if len(rawSalt) != 4 {
+		err = errors.New("SQL Server salt must be 4 bytes")
+		return
+	}
+
+	// The password is hashed as UCS-2LE, followed by the salt.
+	hm := h()
+	hm.Write(new(NTHash).UTF8ToUCS2LE(password))
+	hm.Write(rawSalt)
+	buf := append(rawSalt, hm.Sum(nil)...)
+
+	// Make hash with magic and the upper case hex of the salt and digest.
+	hash = append([]byte(a.Magic), bytes.ToUpper([]byte(hex.EncodeToString(buf)))...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with SQL Server's standard.
+func (a *MSSQL) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// Check a password hash with the salt stored in it, ignoring the case of the hex.
+func (a *MSSQL) CheckPassword(hash []byte, password []byte) (bool, error) {
+	// The salt follows the version header.
+	if len(hash) < len(a.Magic)+8 || !bytes.EqualFold(hash[:len(a.Magic)], []byte(a.Magic)) {
+		return false, errors.New("SQL Server hash does not match the version")
+	}
+	salt := hash[len(a.Magic) : len(a.Magic)+8]
+
+	newHash, err := a.HashPasswordWithSalt(password, salt)
+	if err != nil {
+		return false, err
+	}
+	return bytes.EqualFold(hash, newHash), nil
+}
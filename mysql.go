@@ -0,0 +1,173 @@
+package passwd
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type MySQLNative struct {
+	Passwd
+}
+
+type MySQLOld struct {
+	Passwd
+}
+
+type MySQLCachingSHA2 struct {
+	Passwd
+}
+
+// Make a MySQL native password instance, as used by mysql_native_password.
+func NewMySQLNativePasswd() PasswdInterface {
+	m := new(MySQLNative)
+	m.Magic = MYSQL_NATIVE_MAGIC
+	// MySQL native passwords have no salt, so we disable it.
+	m.SaltLength = -1
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a MySQL pre-4.1 password instance, as used by OLD_PASSWORD().
+func NewMySQLOldPasswd() PasswdInterface {
+	m := new(MySQLOld)
+	m.Magic = MYSQL_OLD_MAGIC
+	// MySQL old passwords have no salt, so we disable it.
+	m.SaltLength = -1
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Hash a MySQL native password, which is SHA1 applied twice.
+func (a *MySQLNative) Hash(password []byte) (hash []byte) {
+	h := sha1.New()
+	h.Write(password)
+	stage1 := h.Sum(nil)
+	h.Reset()
+	h.Write(stage1)
+	buf := h.Sum(nil)
+
+	// Upper case hex encode the SHA1 hash.
+	dst := make([]byte, hex.EncodedLen(len(buf)))
+	hex.Encode(dst, buf)
+
+	// Make hash with magic.
+	hash = append([]byte(a.Magic), bytes.ToUpper(dst)...)
+	return
+}
+
+// Override the hash with salt function with one that encodes the MySQL native password, ignoring the salt.
+func (a *MySQLNative) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash = a.Hash(password)
+	return
+}
+
+// Hash a MySQL pre-4.1 password.
+func (a *MySQLOld) Hash(password []byte) (hash []byte) {
+	var nr, add, nr2 uint32 = 1345345333, 7, 0x12345671
+	for _, c := range password {
+		// Spaces and tabs are skipped.
+		if c == ' ' || c == '\t' {
+			continue
+		}
+		tmp := uint32(c)
+		nr ^= (((nr & 63) + add) * tmp) + (nr << 8)
+		nr2 += (nr2 << 8) ^ nr
+		add += tmp
+	}
+
+	// Make hash with the 31 bit results.
+	hash = []byte(fmt.Sprintf("%s%08x%08x", a.Magic, nr&0x7FFFFFFF, nr2&0x7FFFFFFF))
+	return
+}
+
+// Override the hash with salt function with one that encodes the MySQL old password, ignoring the salt.
+func (a *MySQLOld) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash = a.Hash(password)
+	return
+}
+
+// Make a MySQL caching_sha2_password instance.
+func NewMySQLCachingSHA2Passwd() PasswdInterface {
+	m := new(MySQLCachingSHA2)
+	m.Magic = MYSQL_SHA2_MAGIC
+	m.SetIterations(5000)
+	// The salt is 20 raw bytes.
+	m.SaltLength = 20
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Sets the iterations, which are stored as a multiple of 1000.
+func (a *MySQLCachingSHA2) SetIterations(iterations uint64) (err error) {
+	if iterations%1000 != 0 || iterations == 0 || iterations > 0xFFF*1000 {
+		return errors.New("caching_sha2_password iterations must be a multiple of 1000 up to 4095000")
+	}
+	a.Params = fmt.Sprintf("%03X", iterations/1000)
+	return
+}
+
+// Decode the iterations.
+func (a *MySQLCachingSHA2) DecodeIterations() (iterations uint64, err error) {
+	count, err := strconv.ParseUint(a.Params, 16, 64)
+	if err != nil {
+		return
+	}
+	iterations = count * 1000
+	return
+}
+
+// Generate a salt as MySQL does, which is 7 bit without null or $ characters.
+func (a *MySQLCachingSHA2) GenerateSalt() ([]byte, error) {
+	salt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	for i := range salt {
+		salt[i] &= 0x7F
+		if salt[i] == 0 || salt[i] == '$' {
+			salt[i]++
+		}
+	}
+	return salt, nil
+}
+
+// Hash a password with salt using the MySQL caching_sha2_password standard.
+func (a *MySQLCachingSHA2) Hash(password []byte, salt []byte, iterations uint64) (hash []byte, err error) {
+	// Salt must be 20 bytes.
+	if len(salt) < 20 {
+		err = errors.New("caching_sha2_password salt must be 20 bytes")
+		return
+	}
+	salt = salt[:20]
+	if iterations%1000 != 0 || iterations == 0 || iterations > 0xFFF*1000 {
+		err = errors.New("caching_sha2_password iterations must be a multiple of 1000 up to 4095000")
+		return
+	}
+
+	// The digest is SHA256 crypt with the full salt.
+	result := sha256CryptSum(password, salt, iterations)
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%03X$", a.Magic, iterations/1000))
+	hash = append(hash, salt...)
+	hash = append(hash, Base64RotateEncode(result, false)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with MySQL caching_sha2_password.
+func (a *MySQLCachingSHA2) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	iterations, err := a.DecodeIterations()
+	if err != nil {
+		return
+	}
+
+	hash, err = a.Hash(password, salt, iterations)
+	return
+}
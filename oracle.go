@@ -0,0 +1,178 @@
+package passwd
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+type Oracle struct {
+	Passwd
+}
+
+// Make an Oracle 11g S: verifier instance, which is a salted SHA1.
+func NewOracle11gPasswd() PasswdInterface {
+	return newOraclePasswd(ORACLE_S_MAGIC, 10)
+}
+
+// Make an Oracle 12c T: verifier instance, which is a salted SHA512 of a PBKDF2 key.
+func NewOracle12cPasswd() PasswdInterface {
+	return newOraclePasswd(ORACLE_T_MAGIC, 16)
+}
+
+// Make an Oracle verifier instance for the magic.
+func newOraclePasswd(magic string, saltLength int) *Oracle {
+	m := new(Oracle)
+	m.Magic = magic
+	// The salt is raw bytes, encoded to hex.
+	m.SaltLength = saltLength
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Generate a salt encoded with upper case hex.
+func (a *Oracle) GenerateSalt() ([]byte, error) {
+	rawSalt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.ToUpper(hex.EncodeToString(rawSalt))), nil
+}
+
+// Hash a password with the hex salt to an Oracle verifier. The password must
+// be in the database character set, which is typically UTF-8.
+func (a *Oracle) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	rawSalt, err := hex.DecodeString(string(salt))
+	if err != nil {
+		return
+	}
+
+	var buf []byte
+	switch a.Magic {
+	case ORACLE_S_MAGIC:
+		if len(rawSalt) != 10 {
+			err = errors.New("Oracle S: salt must be 10 bytes")
+			return
+		}
+		h := sha1.New()
+		h.Write(password)
+		h.Write(rawSalt)
+		buf = h.Sum(nil)
+	case ORACLE_T_MAGIC:
+		if len(rawSalt) != 16 {
+			err = errors.New("Oracle T: salt must be 16 bytes")
+			return
+		}
+		// The key is derived with the salt as the AUTH_VFR_DATA sent to clients.
+		vfrData := append(append([]byte{}, rawSalt...), "AUTH_PBKDF2_SPEEDY_KEY"...)
+		key := pbkdf2.Key(password, vfrData, 4096, SHA512_SIZE, sha512.New)
+		h := sha512.New()
+		h.Write(key)
+		h.Write(rawSalt)
+		buf = h.Sum(nil)
+	default:
+		err = errors.New("unsupported Oracle verifier")
+		return
+	}
+
+	// Make hash with magic and the upper case hex of the digest and salt.
+	buf = append(buf, rawSalt...)
+	hash = append([]byte(a.Magic), bytes.ToUpper([]byte(hex.EncodeToString(buf)))...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with Oracle's standard.
+func (a *Oracle) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// Check a password hash with the salt stored in it, ignoring the case of the hex.
+func (a *Oracle) CheckPassword(hash []byte, password []byte) (bool, error) {
+	// The salt is the hex at the end of the verifier.
+	if !bytes.HasPrefix(hash, []byte(a.Magic)) || len(hash) < len(a.Magic)+a.SaltLength*2 {
+		return false, errors.New("Oracle verifier does not match the type")
+	}
+	salt := hash[len(hash)-a.SaltLength*2:]
+
+	newHash, err := a.HashPasswordWithSalt(password, salt)
+	if err != nil {
+		return false, err
+	}
+	return bytes.EqualFold(hash, newHash), nil
+}
+
+// The verifiers from an Oracle SYS.USER$ spare4 field.
+//
+//	S:<hash><salt>;T:<hash><salt>;H:<hash>
+//
+// The verifiers are stored in the order they appear, and the S: and T:
+// verifiers may be passed directly to CheckPassword.
+type OracleSpare4 struct {
+	Verifiers [][]byte
+}
+
+// Parse an Oracle spare4 field.
+func ParseOracleSpare4(spare4 string) (*OracleSpare4, error) {
+	e := new(OracleSpare4)
+	for _, v := range strings.Split(strings.TrimSpace(spare4), ";") {
+		if v == "" {
+			continue
+		}
+		if len(v) < 2 || v[1] != ':' {
+			return nil, errors.New("Invalid verifier in Oracle spare4 field")
+		}
+
+		// Confirm the verifiers this package supports are valid.
+		if strings.HasPrefix(v, ORACLE_S_MAGIC) || strings.HasPrefix(v, ORACLE_T_MAGIC) {
+			if _, err := NewPasswd(v); err != nil {
+				return nil, err
+			}
+		}
+		e.Verifiers = append(e.Verifiers, []byte(v))
+	}
+	if len(e.Verifiers) == 0 {
+		return nil, errors.New("No verifiers in Oracle spare4 field")
+	}
+	return e, nil
+}
+
+// Get the verifier types present, such as "S" and "T".
+func (e *OracleSpare4) Types() []string {
+	var types []string
+	for _, v := range e.Verifiers {
+		types = append(types, string(v[:1]))
+	}
+	return types
+}
+
+// Get the verifier of a type, nil if not present.
+func (e *OracleSpare4) Verifier(t string) []byte {
+	for _, v := range e.Verifiers {
+		if string(v[:1]) == t {
+			return v
+		}
+	}
+	return nil
+}
+
+// Check a password against the strongest supported verifier present.
+func (e *OracleSpare4) CheckPassword(password []byte) (bool, error) {
+	for _, t := range []string{"T", "S"} {
+		if v := e.Verifier(t); v != nil {
+			return CheckPassword(v, password)
+		}
+	}
+	return false, errors.New("No supported verifier in Oracle spare4 field")
+}
+
+// Format the spare4 field.
+func (e *OracleSpare4) String() string {
+	return string(bytes.Join(e.Verifiers, []byte(";")))
+}
@@ -3,6 +3,8 @@ package passwd
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
@@ -24,6 +26,67 @@ const (
 	S_CRYPT_MAGIC        = "$7$"
 	YES_CRYPT_MAGIC      = "$y$"
 	GOST_YES_CRYPT_MAGIC = "$gy$"
+	BCRYPT_MAGIC         = "$2b$"
+	BCRYPT_A_MAGIC       = "$2a$"
+	BCRYPT_X_MAGIC       = "$2x$"
+	BCRYPT_Y_MAGIC       = "$2y$"
+	BCRYPT_SHA256_MAGIC  = "$bcrypt-sha256$"
+	BCRYPT_SIZE          = 23
+	DES_CRYPT_MAGIC      = ""
+	BSDI_CRYPT_MAGIC     = "_"
+	BIG_CRYPT_MAGIC      = ""
+	CRYPT16_MAGIC        = ""
+	APR1_CRYPT_MAGIC     = "$apr1$"
+	AIX_SMD5_MAGIC       = "{smd5}"
+	AIX_SSHA1_MAGIC      = "{ssha1}"
+	AIX_SSHA256_MAGIC    = "{ssha256}"
+	AIX_SSHA512_MAGIC    = "{ssha512}"
+	ARGON2D_MAGIC        = "$argon2d$"
+	ARGON2I_MAGIC        = "$argon2i$"
+	ARGON2ID_MAGIC       = "$argon2id$"
+	PBKDF2_SHA1_MAGIC    = "$pbkdf2$"
+	PBKDF2_SHA256_MAGIC  = "$pbkdf2-sha256$"
+	PBKDF2_SHA512_MAGIC  = "$pbkdf2-sha512$"
+	PHPASS_MAGIC         = "$P$"
+	PHPASS_H_MAGIC       = "$H$"
+	DRUPAL_SHA512_MAGIC  = "$S$"
+	MYSQL_NATIVE_MAGIC   = "*"
+	MYSQL_OLD_MAGIC      = ""
+	MYSQL_SHA2_MAGIC     = "$A$"
+	POSTGRES_SCRAM_MAGIC = "SCRAM-SHA-256$"
+	POSTGRES_MD5_MAGIC   = "md5"
+	LDAP_CRYPT_MAGIC     = "{CRYPT}"
+	CISCO_TYPE8_MAGIC    = "$8$"
+	CISCO_TYPE9_MAGIC    = "$9$"
+	LM_HASH_MAGIC        = ""
+	MSSQL_2005_MAGIC     = "0x0100"
+	MSSQL_2012_MAGIC     = "0x0200"
+	ORACLE_S_MAGIC       = "S:"
+	ORACLE_T_MAGIC       = "T:"
+	SPRING_BCRYPT_MAGIC  = "{bcrypt}"
+	SPRING_PBKDF2_MAGIC  = "{pbkdf2}"
+	SPRING_SCRYPT_MAGIC  = "{scrypt}"
+	SPRING_SHA256_MAGIC  = "{sha256}"
+	SPRING_NOOP_MAGIC    = "{noop}"
+)
+
+// Django prefixes the hasher algorithm name.
+const (
+	DJANGO_PBKDF2_SHA256_MAGIC = "pbkdf2_sha256$"
+	DJANGO_PBKDF2_SHA1_MAGIC   = "pbkdf2_sha1$"
+	DJANGO_ARGON2_MAGIC        = "argon2"
+	DJANGO_BCRYPT_SHA256_MAGIC = "bcrypt_sha256$"
+	DJANGO_BCRYPT_MAGIC        = "bcrypt$"
+	DJANGO_SCRYPT_MAGIC        = "scrypt$"
+	DJANGO_MD5_MAGIC           = "md5$"
+	DJANGO_SHA1_MAGIC          = "sha1$"
+	DJANGO_CRYPT_MAGIC         = "crypt$$"
+)
+
+// Werkzeug prefixes the method, with parameters separated by colons.
+const (
+	WERKZEUG_PBKDF2_MAGIC = "pbkdf2:"
+	WERKZEUG_SCRYPT_MAGIC = "scrypt:"
 )
 
 // Standard protocol for working with all hash algorithms.
@@ -35,6 +98,12 @@ type PasswdInterface interface {
 	HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error)
 }
 
+// Optional protocol for algorithms where a hash can not be verified by hashing
+// the password with the same settings and comparing the result.
+type PasswdChecker interface {
+	CheckPassword(hash []byte, password []byte) (bool, error)
+}
+
 // Base structure.
 type Passwd struct {
 	Magic      string
@@ -112,6 +181,55 @@ func NewPasswd(settings string) (PasswdInterface, error) {
 		return passwd, nil
 	}
 
+	// Apache MD5 $apr1$<salt>[$]
+	if strings.HasPrefix(settings, APR1_CRYPT_MAGIC) {
+		s := strings.Split(settings[len(APR1_CRYPT_MAGIC):], "$")
+
+		// Make the interface.
+		passwd := NewAPR1Passwd()
+		passwd.SetSalt([]byte(s[0]))
+		return passwd, nil
+	}
+
+	// AIX MD5 {smd5}[$1$]<salt>$[<hash>]
+	if strings.HasPrefix(settings, AIX_SMD5_MAGIC) && strings.Contains(settings, "$") {
+		settings = settings[len(AIX_SMD5_MAGIC):]
+
+		// AIX may be configured to include the standard magic in the digest.
+		passwd := NewAIXMD5Passwd().(*MD5Crypt)
+		if strings.HasPrefix(settings, MD5_CRYPT_MAGIC) {
+			passwd.Magic = MD5_CRYPT_MAGIC
+			settings = settings[len(MD5_CRYPT_MAGIC):]
+		}
+		s := strings.Split(settings, "$")
+
+		// Make the interface.
+		passwd.SetSalt([]byte(s[0]))
+		return passwd, nil
+	}
+
+	// AIX salted SHA {ssha1}<cost>$<salt>$[<hash>], also {ssha256} and {ssha512}
+	if strings.HasPrefix(settings, AIX_SSHA1_MAGIC) || strings.HasPrefix(settings, AIX_SSHA256_MAGIC) ||
+		strings.HasPrefix(settings, AIX_SSHA512_MAGIC) {
+		magic := settings[:strings.Index(settings, "}")+1]
+		s := strings.Split(settings[len(magic):], "$")
+
+		// LDAP salted SHA hashes share the prefix, so only take a two digit
+		// cost followed by the salt, otherwise fall through to the LDAP parser.
+		if len(s) >= 3 && len(s[0]) == 2 && s[0][0] >= '0' && s[0][0] <= '9' && s[0][1] >= '0' && s[0][1] <= '9' {
+			cost, err := strconv.Atoi(s[0])
+			if err != nil {
+				return nil, err
+			}
+
+			// Make the interface.
+			passwd := newAIXSSHAPasswd(magic)
+			passwd.SetCost(cost)
+			passwd.SetSalt([]byte(s[1]))
+			return passwd, nil
+		}
+	}
+
 	// NT $3$[$]
 	if strings.HasPrefix(settings, NT_HASH_MAGIC) {
 		// Make the interface.
@@ -203,7 +321,7 @@ func NewPasswd(settings string) (PasswdInterface, error) {
 		return passwd, nil
 	}
 
-	// Yes Crypt $y$j<N><r>$<salt>[$]
+	// Yes Crypt $y$<flavor><N><r>[<have><p><t><g><NROM>]$<salt>[$]
 	if strings.HasPrefix(settings, YES_CRYPT_MAGIC) {
 		s := strings.Split(settings[len(YES_CRYPT_MAGIC):], "$")
 
@@ -212,8 +330,8 @@ func NewPasswd(settings string) (PasswdInterface, error) {
 			return nil, errors.New("Too few parameters for Yes Crypt hash")
 		}
 
-		if len(s[0]) != 3 {
-			return nil, errors.New("Invalid length for Yes Crypt parameters")
+		if _, err := DecodeYescryptParams(s[0]); err != nil {
+			return nil, err
 		}
 
 		// Make the interface.
@@ -223,7 +341,7 @@ func NewPasswd(settings string) (PasswdInterface, error) {
 		return passwd, nil
 	}
 
-	// Gost Yes Crypt $gy$j<N><r>$<salt>[$]
+	// Gost Yes Crypt $gy$<flavor><N><r>[<have><p><t><g><NROM>]$<salt>[$]
 	if strings.HasPrefix(settings, GOST_YES_CRYPT_MAGIC) {
 		s := strings.Split(settings[len(GOST_YES_CRYPT_MAGIC):], "$")
 
@@ -232,8 +350,8 @@ func NewPasswd(settings string) (PasswdInterface, error) {
 			return nil, errors.New("Too few parameters for Gost Yes Crypt hash")
 		}
 
-		if len(s[0]) != 3 {
-			return nil, errors.New("Invalid length for Gost Yes Crypt parameters")
+		if _, err := DecodeYescryptParams(s[0]); err != nil {
+			return nil, err
 		}
 
 		// Make the interface.
@@ -243,6 +361,529 @@ func NewPasswd(settings string) (PasswdInterface, error) {
 		return passwd, nil
 	}
 
+	// BCrypt $2[abxy]$<cost>$<salt><hash>
+	if strings.HasPrefix(settings, BCRYPT_MAGIC) || strings.HasPrefix(settings, BCRYPT_A_MAGIC) ||
+		strings.HasPrefix(settings, BCRYPT_X_MAGIC) || strings.HasPrefix(settings, BCRYPT_Y_MAGIC) {
+		magic := settings[:len(BCRYPT_MAGIC)]
+		s := strings.Split(settings[len(magic):], "$")
+
+		// If less than 2 options, this is not a valid setting.
+		if len(s) < 2 {
+			return nil, errors.New("Too few parameters for BCrypt hash")
+		}
+
+		// The cost is always two digits.
+		if len(s[0]) != 2 {
+			return nil, errors.New("Invalid length for BCrypt cost")
+		}
+		cost, err := strconv.ParseUint(s[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// The salt is the first 22 characters, the rest is the hash.
+		if len(s[1]) < 22 {
+			return nil, errors.New("Too few characters in salt for BCrypt")
+		}
+
+		// Make the interface.
+		passwd := NewBcryptVariantPasswd(magic)
+		passwd.SetParams(fmt.Sprintf("cost=%d", cost))
+		passwd.SetSalt([]byte(s[1][:22]))
+		return passwd, nil
+	}
+
+	// Passlib bcrypt-sha256 $bcrypt-sha256$<v=2,t=2b,r=<cost>|2a,<cost>>$<salt>[$<hash>]
+	if strings.HasPrefix(settings, BCRYPT_SHA256_MAGIC) {
+		s := strings.Split(settings[len(BCRYPT_SHA256_MAGIC):], "$")
+
+		// If less than 2 options, this is not a valid setting.
+		if len(s) < 2 {
+			return nil, errors.New("Too few parameters for bcrypt-sha256 hash")
+		}
+
+		// Make the interface.
+		passwd := NewBcryptSHA256Passwd().(*BcryptSHA256)
+		passwd.SetParams(s[0])
+		if _, _, _, err := passwd.DecodeBcryptSHA256Params(); err != nil {
+			return nil, err
+		}
+		passwd.SetSalt([]byte(s[1]))
+		return passwd, nil
+	}
+
+	// Argon2 $argon2<d|i|id>$[v=<version>$]m=<memory>,t=<time>,p=<threads>$<salt>[$<hash>]
+	if strings.HasPrefix(settings, ARGON2D_MAGIC) || strings.HasPrefix(settings, ARGON2I_MAGIC) ||
+		strings.HasPrefix(settings, ARGON2ID_MAGIC) {
+		magic := settings[:strings.Index(settings[1:], "$")+2]
+		s := strings.Split(settings[len(magic):], "$")
+
+		// Hashes from before version 1.3 have no version parameter.
+		params := s[0]
+		if strings.HasPrefix(s[0], "v=") {
+			if len(s) < 2 {
+				return nil, errors.New("Too few parameters for Argon2 hash")
+			}
+			params = s[0] + "$" + s[1]
+			s = s[1:]
+		}
+
+		// If less than 2 options, this is not a valid setting.
+		if len(s) < 2 {
+			return nil, errors.New("Too few parameters for Argon2 hash")
+		}
+
+		// Make the interface.
+		passwd := newArgon2Passwd(magic)
+		passwd.SetParams(params)
+		passwd.SetSalt([]byte(s[1]))
+
+		// The key length is derived from the existing hash.
+		if len(s) > 2 {
+			key, err := base64.RawStdEncoding.DecodeString(s[2])
+			if err != nil {
+				return nil, err
+			}
+			passwd.KeyLength = uint32(len(key))
+		}
+		return passwd, nil
+	}
+
+	// PBKDF2 $pbkdf2[-sha256|-sha512]$<iterations>$<salt>[$]
+	if strings.HasPrefix(settings, PBKDF2_SHA1_MAGIC) || strings.HasPrefix(settings, PBKDF2_SHA256_MAGIC) ||
+		strings.HasPrefix(settings, PBKDF2_SHA512_MAGIC) {
+		magic := settings[:strings.Index(settings[1:], "$")+2]
+		s := strings.Split(settings[len(magic):], "$")
+
+		// If less than 2 options, this is not a valid setting.
+		if len(s) < 2 {
+			return nil, errors.New("Too few parameters for PBKDF2 hash")
+		}
+
+		// Confirm that the iterations can be parsed.
+		iterations, err := strconv.ParseUint(s[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// Make the interface.
+		passwd := newPBKDF2Passwd(magic, strconv.FormatUint(iterations, 10))
+		passwd.SetSalt([]byte(s[1]))
+		return passwd, nil
+	}
+
+	// PHPass $P$<log2 iterations><salt>[<hash>], also $H$ and Drupal $S$
+	if strings.HasPrefix(settings, PHPASS_MAGIC) || strings.HasPrefix(settings, PHPASS_H_MAGIC) ||
+		strings.HasPrefix(settings, DRUPAL_SHA512_MAGIC) {
+		magic := settings[:len(PHPASS_MAGIC)]
+		s := []byte(settings[len(magic):])
+
+		// The iterations are 1 character, and the salt is 8 characters.
+		if len(s) < 9 {
+			return nil, errors.New("Too few characters in settings for phpass hash")
+		}
+
+		// Make the interface.
+		passwd := newPHPassPasswd(magic, 0)
+		passwd.SetParams(string(s[:1]))
+		passwd.SetSalt(s[1:9])
+		return passwd, nil
+	}
+
+	// Cisco type 8 $8$<salt>$[<hash>] and type 9 $9$<salt>$[<hash>]
+	if strings.HasPrefix(settings, CISCO_TYPE8_MAGIC) || strings.HasPrefix(settings, CISCO_TYPE9_MAGIC) {
+		s := strings.Split(settings[3:], "$")
+
+		// The salt is always 14 characters.
+		if len(s[0]) != 14 {
+			return nil, errors.New("Cisco salt must be 14 characters")
+		}
+
+		// Make the interface.
+		passwd := NewCiscoType8Passwd()
+		if strings.HasPrefix(settings, CISCO_TYPE9_MAGIC) {
+			passwd = NewCiscoType9Passwd()
+		}
+		passwd.SetSalt([]byte(s[0]))
+		return passwd, nil
+	}
+
+	// MySQL caching_sha2_password $A$<iterations/1000>$<salt>[<hash>]
+	if strings.HasPrefix(settings, MYSQL_SHA2_MAGIC) {
+		s := settings[len(MYSQL_SHA2_MAGIC):]
+
+		// The iterations are 3 hex characters, and the salt is 20 bytes which may contain any character.
+		if len(s) < 24 || s[3] != '$' {
+			return nil, errors.New("Too few characters in settings for caching_sha2_password hash")
+		}
+		if _, err := strconv.ParseUint(s[:3], 16, 64); err != nil {
+			return nil, err
+		}
+
+		// Make the interface.
+		passwd := NewMySQLCachingSHA2Passwd()
+		passwd.SetParams(s[:3])
+		passwd.SetSalt([]byte(s[4:24]))
+		return passwd, nil
+	}
+
+	// BSDi _<rounds><salt>[<hash>]
+	if strings.HasPrefix(settings, BSDI_CRYPT_MAGIC) {
+		s := []byte(settings[len(BSDI_CRYPT_MAGIC):])
+
+		// The rounds and salt are each 4 characters.
+		if len(s) < 8 {
+			return nil, errors.New("Too few characters in settings for BSDi hash")
+		}
+		if !validIota64(s[:8]) {
+			return nil, errors.New("Invalid characters in settings for BSDi hash")
+		}
+		iterations := Base64Uint32Decode(s[:4], 24)
+
+		// Make the interface.
+		passwd := NewBSDiCryptPasswd()
+		passwd.SetParams(fmt.Sprintf("rounds=%d", iterations))
+		passwd.SetSalt(s[4:8])
+		return passwd, nil
+	}
+
+	// LDAP {<scheme>}<value>
+	if scheme, value, ok := parseLDAPScheme(settings); ok {
+		// The crypt scheme wraps a crypt hash.
+		if strings.EqualFold(scheme, "CRYPT") {
+			crypt, err := NewPasswd(value)
+			if err != nil {
+				return nil, err
+			}
+
+			// Make the interface.
+			passwd := NewLDAPCryptPasswd(crypt).(*LDAPCrypt)
+			passwd.Magic = settings[:len(settings)-len(value)]
+			return passwd, nil
+		}
+
+		// Other schemes are a base64 encoded digest with an optional salt.
+		if passwd, err := NewLDAPPasswd(scheme); err == nil {
+			s := ldapSchemes[strings.ToUpper(scheme)]
+			size := s.h().Size()
+			buf, err := base64.StdEncoding.DecodeString(value)
+			if err == nil && (len(buf) == size || (s.salted && len(buf) > size)) {
+				passwd.SetSalt(buf[size:])
+				return passwd, nil
+			}
+		}
+	}
+
+	// Spring Security {bcrypt}<bcrypt hash>
+	if strings.HasPrefix(settings, SPRING_BCRYPT_MAGIC) {
+		crypt, err := NewPasswd(settings[len(SPRING_BCRYPT_MAGIC):])
+		if err != nil {
+			return nil, err
+		}
+
+		// Make the interface.
+		passwd := NewSpringBcryptWrapPasswd(crypt)
+		return passwd, nil
+	}
+
+	// Spring Security {pbkdf2}<hex salt><hex hash>
+	if strings.HasPrefix(settings, SPRING_PBKDF2_MAGIC) {
+		buf, err := hex.DecodeString(settings[len(SPRING_PBKDF2_MAGIC):])
+		if err != nil {
+			return nil, err
+		}
+
+		// The defaults are only identifiable by the salt length.
+		var passwd PasswdInterface
+		switch len(buf) {
+		case 16 + SHA256_SIZE:
+			passwd = NewSpringPBKDF2Passwd()
+			passwd.SetSalt(buf[:16])
+		case 8 + SHA256_SIZE:
+			passwd = NewSpringPBKDF2SHA1Passwd()
+			passwd.SetSalt(buf[:8])
+		default:
+			return nil, errors.New("Unknown salt length for Spring PBKDF2 hash")
+		}
+		return passwd, nil
+	}
+
+	// Spring Security {scrypt}$<params>$<salt>[$<hash>]
+	if strings.HasPrefix(settings, SPRING_SCRYPT_MAGIC) {
+		s := strings.Split(settings[len(SPRING_SCRYPT_MAGIC):], "$")
+
+		// If less than 3 options, this is not a valid setting.
+		if len(s) < 3 || s[0] != "" {
+			return nil, errors.New("Too few parameters for Spring scrypt hash")
+		}
+		if _, err := strconv.ParseUint(s[1], 16, 64); err != nil {
+			return nil, err
+		}
+		salt, err := base64.StdEncoding.DecodeString(s[2])
+		if err != nil {
+			return nil, err
+		}
+
+		// Make the interface.
+		passwd := NewSpringSCryptPasswd().(*SpringSCrypt)
+		passwd.SetParams(s[1])
+		passwd.SetSalt(salt)
+
+		// The key length is configurable, so use the length of the stored key.
+		if len(s) > 3 && s[3] != "" {
+			key, err := base64.StdEncoding.DecodeString(s[3])
+			if err != nil {
+				return nil, err
+			}
+			passwd.SetKeyLength(len(key))
+		}
+		return passwd, nil
+	}
+
+	// Spring Security {sha256}<hex salt><hex hash>
+	if strings.HasPrefix(settings, SPRING_SHA256_MAGIC) {
+		buf, err := hex.DecodeString(settings[len(SPRING_SHA256_MAGIC):])
+		if err != nil {
+			return nil, err
+		}
+		if len(buf) != 8+SHA256_SIZE {
+			return nil, errors.New("Invalid length for Spring SHA256 hash")
+		}
+
+		// Make the interface.
+		passwd := NewSpringSHA256Passwd()
+		passwd.SetSalt(buf[:8])
+		return passwd, nil
+	}
+
+	// Spring Security {noop}<password>
+	if strings.HasPrefix(settings, SPRING_NOOP_MAGIC) {
+		// Make the interface.
+		passwd := NewSpringNoopPasswd()
+		return passwd, nil
+	}
+
+	// Django pbkdf2_sha256$<iterations>$<salt>[$<hash>]
+	if strings.HasPrefix(settings, DJANGO_PBKDF2_SHA256_MAGIC) || strings.HasPrefix(settings, DJANGO_PBKDF2_SHA1_MAGIC) {
+		magic := DJANGO_PBKDF2_SHA256_MAGIC
+		if strings.HasPrefix(settings, DJANGO_PBKDF2_SHA1_MAGIC) {
+			magic = DJANGO_PBKDF2_SHA1_MAGIC
+		}
+		s := strings.Split(settings[len(magic):], "$")
+
+		// If less than 2 options, this is not a valid setting.
+		if len(s) < 2 {
+			return nil, errors.New("Too few parameters for Django PBKDF2 hash")
+		}
+		if _, err := strconv.ParseUint(s[0], 10, 64); err != nil {
+			return nil, err
+		}
+
+		// Make the interface.
+		passwd := newDjangoPBKDF2Passwd(magic)
+		passwd.SetParams(s[0])
+		passwd.SetSalt([]byte(s[1]))
+		return passwd, nil
+	}
+
+	// Django argon2$argon2<variant>$<argon2 hash>, bcrypt$<bcrypt hash>, and crypt$$<DES crypt hash>
+	for _, magic := range []string{DJANGO_ARGON2_MAGIC, DJANGO_BCRYPT_MAGIC, DJANGO_CRYPT_MAGIC} {
+		if strings.HasPrefix(settings, magic) {
+			crypt, err := NewPasswd(settings[len(magic):])
+			if err != nil {
+				return nil, err
+			}
+
+			// Make the interface.
+			passwd := NewPrefixPasswd(magic, crypt)
+			return passwd, nil
+		}
+	}
+
+	// Django bcrypt_sha256$<bcrypt hash>
+	if strings.HasPrefix(settings, DJANGO_BCRYPT_SHA256_MAGIC) {
+		crypt, err := NewPasswd(settings[len(DJANGO_BCRYPT_SHA256_MAGIC):])
+		if err != nil {
+			return nil, err
+		}
+
+		// Make the interface.
+		passwd := NewDjangoBcryptSHA256WrapPasswd(crypt)
+		return passwd, nil
+	}
+
+	// Django scrypt$<N>$<salt>$<r>$<p>[$<hash>]
+	if strings.HasPrefix(settings, DJANGO_SCRYPT_MAGIC) {
+		s := strings.Split(settings[len(DJANGO_SCRYPT_MAGIC):], "$")
+
+		// If less than 4 options, this is not a valid setting.
+		if len(s) < 4 {
+			return nil, errors.New("Too few parameters for Django scrypt hash")
+		}
+		N, err := strconv.Atoi(s[0])
+		if err != nil {
+			return nil, err
+		}
+		r, err := strconv.Atoi(s[2])
+		if err != nil {
+			return nil, err
+		}
+		p, err := strconv.Atoi(s[3])
+		if err != nil {
+			return nil, err
+		}
+
+		// Make the interface.
+		passwd := NewDjangoSCryptPasswd().(*DjangoSCrypt)
+		passwd.SetSCryptParams(N, r, p)
+		passwd.SetSalt([]byte(s[1]))
+		return passwd, nil
+	}
+
+	// Django md5$<salt>$<hash> and sha1$<salt>$<hash>, the salt is empty for unsalted hashes.
+	if strings.HasPrefix(settings, DJANGO_MD5_MAGIC) || strings.HasPrefix(settings, DJANGO_SHA1_MAGIC) {
+		magic := DJANGO_MD5_MAGIC
+		if strings.HasPrefix(settings, DJANGO_SHA1_MAGIC) {
+			magic = DJANGO_SHA1_MAGIC
+		}
+		s := strings.Split(settings[len(magic):], "$")
+
+		// Make the interface.
+		if s[0] == "" {
+			passwd := newDjangoHashPasswd(magic, -1)
+			return passwd, nil
+		}
+		passwd := newDjangoHashPasswd(magic, 22)
+		passwd.SetSalt([]byte(s[0]))
+		return passwd, nil
+	}
+
+	// Werkzeug pbkdf2:<hash name>:<iterations>$<salt>[$<hash>] and scrypt:<N>:<r>:<p>$<salt>[$<hash>]
+	if strings.HasPrefix(settings, WERKZEUG_PBKDF2_MAGIC) || strings.HasPrefix(settings, WERKZEUG_SCRYPT_MAGIC) {
+		s := strings.Split(settings, "$")
+
+		// If less than 2 options, this is not a valid setting.
+		if len(s) < 2 {
+			return nil, errors.New("Too few parameters for Werkzeug hash")
+		}
+
+		// Make the interface.
+		var passwd PasswdInterface
+		if strings.HasPrefix(settings, WERKZEUG_PBKDF2_MAGIC) {
+			pbkdf2 := NewWerkzeugPBKDF2Passwd().(*WerkzeugPBKDF2)
+			pbkdf2.SetParams(s[0][len(WERKZEUG_PBKDF2_MAGIC):])
+			if _, _, err := pbkdf2.DecodePBKDF2Params(); err != nil {
+				return nil, err
+			}
+			passwd = pbkdf2
+		} else {
+			scrypt := NewWerkzeugSCryptPasswd().(*WerkzeugSCrypt)
+			scrypt.SetParams(s[0][len(WERKZEUG_SCRYPT_MAGIC):])
+			if _, _, _, err := scrypt.DecodeSCryptParams(); err != nil {
+				return nil, err
+			}
+			passwd = scrypt
+		}
+		passwd.SetSalt([]byte(s[1]))
+		return passwd, nil
+	}
+
+	// PostgreSQL SCRAM-SHA-256$<iterations>:<salt>[$<stored key>:<server key>]
+	if strings.HasPrefix(settings, POSTGRES_SCRAM_MAGIC) {
+		s := strings.Split(settings[len(POSTGRES_SCRAM_MAGIC):], "$")
+		p := strings.Split(s[0], ":")
+
+		// If less than 2 options, this is not a valid setting.
+		if len(p) < 2 {
+			return nil, errors.New("Too few parameters for SCRAM-SHA-256 hash")
+		}
+
+		// Confirm that the iterations can be parsed.
+		iterations, err := strconv.ParseUint(p[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// Make the interface.
+		passwd := NewPostgresSCRAMPasswd()
+		passwd.SetParams(strconv.FormatUint(iterations, 10))
+		passwd.SetSalt([]byte(p[1]))
+		return passwd, nil
+	}
+
+	// PostgreSQL md5<hash>, the username must be provided as the salt.
+	if strings.HasPrefix(settings, POSTGRES_MD5_MAGIC) && len(settings) == 35 {
+		if _, err := hex.DecodeString(settings[len(POSTGRES_MD5_MAGIC):]); err == nil {
+			// Make the interface.
+			passwd := NewPostgresMD5Passwd()
+			return passwd, nil
+		}
+	}
+
+	// Oracle S:<SHA1 hash><salt> and T:<SHA512 hash><salt>
+	if (strings.HasPrefix(settings, ORACLE_S_MAGIC) && len(settings) == 2+(SHA1_SIZE+10)*2) ||
+		(strings.HasPrefix(settings, ORACLE_T_MAGIC) && len(settings) == 2+(SHA512_SIZE+16)*2) {
+		if _, err := hex.DecodeString(settings[2:]); err == nil {
+			// Make the interface.
+			passwd := NewOracle11gPasswd().(*Oracle)
+			if strings.HasPrefix(settings, ORACLE_T_MAGIC) {
+				passwd = NewOracle12cPasswd().(*Oracle)
+			}
+			passwd.SetSalt([]byte(settings[len(settings)-passwd.SaltLength*2:]))
+			return passwd, nil
+		}
+	}
+
+	// Microsoft SQL Server 0x0100<salt><SHA1 hash> and 0x0200<salt><SHA512 hash>
+	if len(settings) == 6+8+SHA1_SIZE*2 || len(settings) == 6+8+SHA512_SIZE*2 {
+		magic := MSSQL_2005_MAGIC
+		if len(settings) == 6+8+SHA512_SIZE*2 {
+			magic = MSSQL_2012_MAGIC
+		}
+		if strings.EqualFold(settings[:len(magic)], magic) {
+			if _, err := hex.DecodeString(settings[len(magic):]); err == nil {
+				// Make the interface.
+				passwd := newMSSQLPasswd(magic)
+				passwd.SetSalt([]byte(settings[len(magic) : len(magic)+8]))
+				return passwd, nil
+			}
+		}
+	}
+
+	// MySQL native *<hash>
+	if strings.HasPrefix(settings, MYSQL_NATIVE_MAGIC) && len(settings) == 41 {
+		if _, err := hex.DecodeString(settings[len(MYSQL_NATIVE_MAGIC):]); err == nil {
+			// Make the interface.
+			passwd := NewMySQLNativePasswd()
+			return passwd, nil
+		}
+	}
+
+	// MySQL old <hash>
+	if len(settings) == 16 {
+		if _, err := hex.DecodeString(settings); err == nil {
+			// Make the interface.
+			passwd := NewMySQLOldPasswd()
+			return passwd, nil
+		}
+	}
+
+	// DES <salt>[<hash>]
+	if (len(settings) == 2 || len(settings) == 13) && validIota64([]byte(settings)) {
+		// Make the interface.
+		passwd := NewDESCryptPasswd()
+		passwd.SetSalt([]byte(settings[:2]))
+		return passwd, nil
+	}
+
+	// Big Crypt <salt><hash>[<hash>...]
+	if len(settings) > 13 && (len(settings)-13)%11 == 0 && validIota64([]byte(settings)) {
+		// Make the interface.
+		passwd := NewBigCryptPasswd()
+		passwd.SetSalt([]byte(settings[:2]))
+		return passwd, nil
+	}
+
 	// End of the line.
 	return nil, errors.New("No valid matching algorithm")
 }
@@ -253,6 +894,9 @@ func CheckPassword(hash []byte, password []byte) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if checker, ok := passwd.(PasswdChecker); ok {
+		return checker.CheckPassword(hash, password)
+	}
 	newHash, err := passwd.HashPassword(password)
 	if err != nil {
 		return false, err
@@ -263,6 +907,23 @@ func CheckPassword(hash []byte, password []byte) (bool, error) {
 	return false, nil
 }
 
+// Check a password hash against a password, with a salt that is not stored in
+// the hash. Such as the username for PostgreSQL md5 passwords.
+func CheckPasswordWithSalt(hash []byte, password []byte, salt []byte) (bool, error) {
+	passwd, err := NewPasswd(string(hash))
+	if err != nil {
+		return false, err
+	}
+	newHash, err := passwd.HashPasswordWithSalt(password, salt)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(hash, newHash) {
+		return true, nil
+	}
+	return false, nil
+}
+
 // Used internally for salt generation.
 func generateRandomBytes(n uint) ([]byte, error) {
 	b := make([]byte, n)
@@ -274,6 +935,38 @@ func generateRandomBytes(n uint) ([]byte, error) {
 	return b, nil
 }
 
+// Used internally for salts which are a fixed number of crypt base64 characters.
+func generateIota64Salt(n uint) ([]byte, error) {
+	b, err := generateRandomBytes(n)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range b {
+		b[i] = iota64Encoding[b[i]&0x3F]
+	}
+	return b, nil
+}
+
+// Used internally for salts which are alphanumeric characters, like Python's get_random_string.
+func generateAlnumSalt(n int) ([]byte, error) {
+	salt := make([]byte, 0, n)
+	for len(salt) < n {
+		b, err := generateRandomBytes(uint(n))
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range b {
+			// Skip values which would bias the result.
+			if int(c) >= 256-256%len(alnumEncoding) || len(salt) == n {
+				continue
+			}
+			salt = append(salt, alnumEncoding[int(c)%len(alnumEncoding)])
+		}
+	}
+	return salt, nil
+}
+
 // Set parameters for password generation. Typically used for iterations, but also used for yes crypt configuration.
 func (a *Passwd) SetParams(p string) {
 	a.Params = p
@@ -303,7 +996,12 @@ func (a *Passwd) GenerateSalt() ([]byte, error) {
 // Hash a password.
 func (a *Passwd) HashPassword(password []byte) (hash []byte, err error) {
 	if len(a.Salt) == 0 {
-		salt, err := a.GenerateSalt()
+		var salt []byte
+		if a.i != nil {
+			salt, err = a.i.GenerateSalt()
+		} else {
+			salt, err = a.GenerateSalt()
+		}
 		if err != nil {
 			return nil, err
 		}
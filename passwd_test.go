@@ -1,8 +1,12 @@
 package passwd
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPasswd(t *testing.T) {
@@ -43,68 +47,966 @@ func TestPasswd(t *testing.T) {
 		t.Fatalf("Password check for md5 failed")
 	}
 
+	res, err = CheckPassword([]byte("$apr1$abcdefgh$0q48P25AIjKM2mWK/qv4D1"), password)
+	if err != nil {
+		t.Fatalf("apr1 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for apr1 failed")
+	}
+
+	// Confirm passwords longer than the MD5 digest are recycled correctly.
+	res, err = CheckPassword([]byte("$apr1$abcdefgh$VV3XLteg.4tV5ZYJFY4N1."), []byte("ThisIsALongPassword1234"))
+	if err != nil {
+		t.Fatalf("apr1 long error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for apr1 long failed")
+	}
+
+	res, err = CheckPassword([]byte("{smd5}s8/xSJ/v$uGam4GB8hOjTLQqvBfxJ2/"), []byte("password"))
+	if err != nil {
+		t.Fatalf("aix md5 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for aix md5 failed")
+	}
+
 	res, err = CheckPassword([]byte("$3$$4a1fab8f6b5441e0493dc7d41304bfb6"), password)
 	if err != nil {
-		t.Fatalf("nt error: %s", err)
+		t.Fatalf("nt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for nt failed")
+	}
+
+	res, err = CheckPassword([]byte("$5$AsETvlsIoaTP3w6G$OZY9mWRFXR9Pz0Xv1pS2TS/QCpxECLEG/dru/Y.nba/"), password)
+	if err != nil {
+		t.Fatalf("sha256 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for sha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("$5$rounds=243006$oCvhLw/Nn9HuQIm4$VPKzWx9t.NHgmNpVHeSpzQ5y01z4BE14J.bvG8g2yi."), password)
+	if err != nil {
+		t.Fatalf("sha256 with rounds error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for sha256 with rounds failed")
+	}
+
+	res, err = CheckPassword([]byte("$6$zt7D9I3Uu.EhrzEv$j50OCJ3oNdO2Ee7RE9XTDF7dhvrgRwc9NmjJUouk7czn4JTc/A6qLJIT1pMk7FUlTCYCLl6uBHm5NoEboAzIo0"), password)
+	if err != nil {
+		t.Fatalf("sha512 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for sha512 failed")
+	}
+
+	res, err = CheckPassword([]byte("$6$rounds=523044$.zMtRwbPP2sDg5a5$YgKUnqEda6wxkvDMbJoNjNBiFNpX7nP/uDFV3jV4ngmrXlFBua3n8oIi5St/Re8H3WOksLaody3eAhaGtAN0c/"), password)
+	if err != nil {
+		t.Fatalf("sha512 with rounds error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for sha512 with rounds failed")
+	}
+
+	res, err = CheckPassword([]byte("$7$CU..../....PpL3ULxY5DvYyvasS/a4a0$jqgg90svZLt5KQqFTwegHSn1pXU.aKDavZ3Eq8t2wx9"), password)
+	if err != nil {
+		t.Fatalf("scrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for scrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("$y$j9T$G/uoZu1orhwOE/lUtohEa.$SMu/wxtyhBLa5xeRLVnznBx5vE0/VxY7rJZlQX27N84"), password)
+	if err != nil {
+		t.Fatalf("yes crypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for yes crypt failed")
+	}
+
+	res, err = CheckPassword([]byte("$gy$j9T$etkZHzB483TIuw/58Df.N/$7DjHx/8jx.E/VLdyzMIIOJULHoZJ1PNlFl71KXaf0s7"), password)
+	if err != nil {
+		t.Fatalf("gost yes crypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for gost yes crypt failed")
+	}
+
+	res, err = CheckPassword([]byte("$2b$05$/OK.fbVrR/bpIqNJ5ianF.myvukhyE9QfN.o.V/zKNq3j8mZLz/zu"), password)
+	if err != nil {
+		t.Fatalf("bcrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bcrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("$2y$05$/OK.fbVrR/bpIqNJ5ianF.myvukhyE9QfN.o.V/zKNq3j8mZLz/zu"), password)
+	if err != nil {
+		t.Fatalf("bcrypt 2y error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bcrypt 2y failed")
+	}
+
+	// Confirm the bcrypt sign extension bug and safety measures match crypt_blowfish.
+	res, err = CheckPassword([]byte("$2a$05$/OK.fbVrR/bpIqNJ5ianF.nqd1wy.pTMdcvrRWxyiGL2eMz.2a85."), []byte("\xff\xff\xa3"))
+	if err != nil {
+		t.Fatalf("bcrypt 2a error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bcrypt 2a failed")
+	}
+
+	res, err = CheckPassword([]byte("$2b$05$/OK.fbVrR/bpIqNJ5ianF.CE5elHaaO4EbggVDjb8P19RukzXSM3e"), []byte("\xff\xff\xa3"))
+	if err != nil {
+		t.Fatalf("bcrypt 2b 8-bit error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bcrypt 2b 8-bit failed")
+	}
+
+	res, err = CheckPassword([]byte("$2x$05$/OK.fbVrR/bpIqNJ5ianF.o./n25XVfn6oAPaUvHe.Csk4zRfsYPi"), []byte("\xff\xa3345"))
+	if err != nil {
+		t.Fatalf("bcrypt 2x error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bcrypt 2x failed")
+	}
+
+	res, err = CheckPassword([]byte("ab.c/LGCUIB3s"), password)
+	if err != nil {
+		t.Fatalf("des error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for des failed")
+	}
+
+	// Confirm DES truncates to 8 characters and drops the 8th bit.
+	res, err = CheckPassword([]byte("zZPaKhHa4yzLg"), []byte("Testpassword"))
+	if err != nil {
+		t.Fatalf("des truncation error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for des truncation failed")
+	}
+
+	res, err = CheckPassword([]byte("9xC1USwh.IuGE"), []byte("\xc3\xa9t\xe9"))
+	if err != nil {
+		t.Fatalf("des 8-bit error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for des 8-bit failed")
+	}
+
+	res, err = CheckPassword([]byte("_J9..abcdNUlRi75Iq/2"), password)
+	if err != nil {
+		t.Fatalf("bsdi error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bsdi failed")
+	}
+
+	// Confirm BSDi folds passwords longer than 8 characters.
+	res, err = CheckPassword([]byte("_J9..abcds1B4ZDa9XoE"), []byte("Testpassword12345"))
+	if err != nil {
+		t.Fatalf("bsdi long password error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bsdi long password failed")
+	}
+
+	res, err = CheckPassword([]byte("_/...zZ9.CajsfK/gDsU"), []byte("Testpassword12345\xe9"))
+	if err != nil {
+		t.Fatalf("bsdi with rounds error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bsdi with rounds failed")
+	}
+
+	res, err = CheckPassword([]byte("abgmBR.EEBbn2y1CCvitPKpMBPA/uTOXIkU"), []byte("Testpassword12345"))
+	if err != nil {
+		t.Fatalf("bigcrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bigcrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("abgmBR.EEBbn2ARtlpDyjZyM"), []byte("Testpassw"))
+	if err != nil {
+		t.Fatalf("bigcrypt 2 segment error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bigcrypt 2 segment failed")
+	}
+
+	res, err = CheckPassword([]byte("qi8H8R7OM4xMUNMPuRAZxlY."), []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("crypt16 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for crypt16 failed")
+	}
+
+	res, err = CheckPassword([]byte("$argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHRzb21lc2FsdA$ElFvwrftZQYmQw7lzbu2sR74tXFssmxc1sr1wCXSOX0"), password)
+	if err != nil {
+		t.Fatalf("argon2id error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for argon2id failed")
+	}
+
+	res, err = CheckPassword([]byte("$argon2i$v=19$m=256,t=2,p=2$c29tZXNhbHRzb21lc2FsdA$8V76CAu9pteG390WL6+D2VfgLy+q7yzxP0l5yjPJrb4"), password)
+	if err != nil {
+		t.Fatalf("argon2i error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for argon2i failed")
+	}
+
+	res, err = CheckPassword([]byte("$argon2d$v=19$m=256,t=2,p=2$c29tZXNhbHRzb21lc2FsdA$ck873IPOhOQtjhoqu9hjsnS/pdCdtVv1DewAoaaj2QY"), password)
+	if err != nil {
+		t.Fatalf("argon2d error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for argon2d failed")
+	}
+
+	res, err = CheckPassword([]byte("$argon2id$v=16$m=256,t=2,p=2$c29tZXNhbHRzb21lc2FsdA$D7z3ajGoJq6Cwws+4pZXPM7O8YRAnGrKFi1xN0/QlSA"), password)
+	if err != nil {
+		t.Fatalf("argon2id v16 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for argon2id v16 failed")
+	}
+
+	res, err = CheckPassword([]byte("$argon2i$v=16$m=256,t=2,p=2$c29tZXNhbHRzb21lc2FsdA$AyLjA/k7NdYhvNIurPmC2tnN1lGMT08d/Clg3xVMUNE"), password)
+	if err != nil {
+		t.Fatalf("argon2i v16 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for argon2i v16 failed")
+	}
+
+	res, err = CheckPassword([]byte("$argon2d$v=16$m=256,t=2,p=2$c29tZXNhbHRzb21lc2FsdA$r+6JM6v3OSYglq62RMZyiD2brifwfsnxIi7p/QP4p3Q"), password)
+	if err != nil {
+		t.Fatalf("argon2d v16 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for argon2d v16 failed")
+	}
+
+	res, err = CheckPassword([]byte("$argon2id$v=19$m=4096,t=3,p=1$c29tZXNhbHQ$tTvy1pvWIgcenvMSEU/ckw"), password)
+	if err != nil {
+		t.Fatalf("argon2id short key error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for argon2id short key failed")
+	}
+
+	res, err = CheckPassword([]byte("$pbkdf2$131000$9t7be09prfXee2/NOUeotQ$mqZUSHM2UUg266toaLjUPTJ.Ynw"), password)
+	if err != nil {
+		t.Fatalf("pbkdf2 sha1 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for pbkdf2 sha1 failed")
+	}
+
+	res, err = CheckPassword([]byte("$pbkdf2-sha256$29000$9t7be09prfXee2/NOUeotQ$ndfncboQYoiY0wWvvSi6TXB1F7cOUbjPt7wg47J9hR8"), password)
+	if err != nil {
+		t.Fatalf("pbkdf2 sha256 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for pbkdf2 sha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("$pbkdf2-sha512$25000$9t7be09prfXee2/NOUeotQ$kHufbpLXJJtRnwflWSuURSSx1IzmgeOaSxQ79N22eRm68Xhqg9ciqhprZmq/hZ/0K6yjJ0OWg0Zx4ySxd9JIRw"), password)
+	if err != nil {
+		t.Fatalf("pbkdf2 sha512 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for pbkdf2 sha512 failed")
+	}
+
+	res, err = CheckPassword([]byte("$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0"), []byte("test12345"))
+	if err != nil {
+		t.Fatalf("phpass error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for phpass failed")
+	}
+
+	res, err = CheckPassword([]byte("$H$9IQRaTwmfEju7E.dKJop1/8K8gPuCO/"), password)
+	if err != nil {
+		t.Fatalf("phpass phpbb error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for phpass phpbb failed")
+	}
+
+	res, err = CheckPassword([]byte("$S$DabcdEFGHZFcoNbOLnl3M41NEEbXx1cG9WLs3Fiijv2a7ZDk9nmi"), password)
+	if err != nil {
+		t.Fatalf("drupal error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for drupal failed")
+	}
+
+	res, err = CheckPassword([]byte("*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19"), []byte("password"))
+	if err != nil {
+		t.Fatalf("mysql native error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for mysql native failed")
+	}
+
+	res, err = CheckPassword([]byte("5d2e19393cc5ef67"), []byte("password"))
+	if err != nil {
+		t.Fatalf("mysql old error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for mysql old failed")
+	}
+
+	res, err = CheckPassword([]byte("$A$005$ABCDEFGHIJKLMNOPQRSTJp0SXULXc0kiDKos8q4NcQHUWx2zqX2RAkvSeiJe/L8"), password)
+	if err != nil {
+		t.Fatalf("mysql caching sha2 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for mysql caching sha2 failed")
+	}
+
+	// Confirm passwords longer than the SHA256 digest and block are recycled correctly.
+	res, err = CheckPassword([]byte("$A$005$ABCDEFGHIJKLMNOPQRSTqIFhGsyGqWdwCEuy1WuQRNR.gLQxhgE.j/fbh67jxcC"), []byte("ThisIsAVeryLongPasswordOver32BytesAndEvenOver64BytesLongForTesting!"))
+	if err != nil {
+		t.Fatalf("mysql caching sha2 long error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for mysql caching sha2 long failed")
+	}
+
+	res, err = CheckPassword([]byte("$A$005$ABCDEFGHIJKLMNOPQRSTYssZJNXZyreSvPu3GEheXTbi4ILgWr91GeaMbQD5Am6"), []byte("ThisIsALongerPasswordOf40BytesForTesting"))
+	if err != nil {
+		t.Fatalf("mysql caching sha2 long error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for mysql caching sha2 long failed")
+	}
+
+	res, err = CheckPassword([]byte("$5$saltstring$NHGw1crT6I7NY9NrRN6msNghtnAHYXjvibCk1mTbIgA"), []byte("ThisIsAVeryLongPasswordOver32BytesAndEvenOver64BytesLongForTesting!"))
+	if err != nil {
+		t.Fatalf("sha256 long error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for sha256 long failed")
+	}
+
+	res, err = CheckPassword([]byte("SCRAM-SHA-256$4096:9t7be09prfXee2/NOUeotQ==$7xR9TIvG0/gm12zSP+/nyCw6bqUfEnlXbjcSdeg8URI=:X457Ka/OWF2o/ZKpSSL7EKBzDhHuEYUxoLzUD9UuOK4="), password)
+	if err != nil {
+		t.Fatalf("postgres scram error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for postgres scram failed")
+	}
+
+	res, err = CheckPasswordWithSalt([]byte("md53ff15df3810ff5d0a42ce299b8cfc857"), password, []byte("postgres"))
+	if err != nil {
+		t.Fatalf("postgres md5 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for postgres md5 failed")
+	}
+
+	res, err = CheckPassword([]byte("{SHA}ZAqyuuB77cTBY/Z5p0b3q3+10fo="), password)
+	if err != nil {
+		t.Fatalf("ldap sha error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for ldap sha failed")
+	}
+
+	res, err = CheckPassword([]byte("{SSHA}r5DQgIWVlPd71U6T4Ht8Qmrf1/cBAgMEBQYHCA=="), password)
+	if err != nil {
+		t.Fatalf("ldap ssha error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for ldap ssha failed")
+	}
+
+	res, err = CheckPassword([]byte("{SSHA256}yERMhNfET/R1mIFgBU6RJpSV0C/PN8pg99dKBXocReABAgMEBQYHCA=="), password)
+	if err != nil {
+		t.Fatalf("ldap ssha256 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for ldap ssha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("{SSHA512}82hhQ+nxTCy4jYZQlU1PQZ00UXo4nGdK0IFLPf5Twahd47Qz8HR8SowOM+9iL+euGqDzAh7+IHr+dX3NSWZ/oAECAwQFBgcI"), password)
+	if err != nil {
+		t.Fatalf("ldap ssha512 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for ldap ssha512 failed")
+	}
+
+	res, err = CheckPassword([]byte("{MD5}DLxmEfVUC9CAmjiNyVphWw=="), password)
+	if err != nil {
+		t.Fatalf("ldap md5 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for ldap md5 failed")
+	}
+
+	res, err = CheckPassword([]byte("{SMD5}xPIFEg5PpCEplv8ARGkxvQECAwQFBgcI"), password)
+	if err != nil {
+		t.Fatalf("ldap smd5 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for ldap smd5 failed")
+	}
+
+	res, err = CheckPassword([]byte("{CRYPT}$6$zt7D9I3Uu.EhrzEv$j50OCJ3oNdO2Ee7RE9XTDF7dhvrgRwc9NmjJUouk7czn4JTc/A6qLJIT1pMk7FUlTCYCLl6uBHm5NoEboAzIo0"), password)
+	if err != nil {
+		t.Fatalf("ldap crypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for ldap crypt failed")
+	}
+
+	res, err = CheckPassword([]byte("{crypt}$y$j9T$G/uoZu1orhwOE/lUtohEa.$SMu/wxtyhBLa5xeRLVnznBx5vE0/VxY7rJZlQX27N84"), password)
+	if err != nil {
+		t.Fatalf("ldap crypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for ldap crypt failed")
+	}
+
+	res, err = CheckPassword([]byte("$1$mERr$vO3Dj7evxXQbzbeOY8ymV1"), password)
+	if err != nil {
+		t.Fatalf("cisco type 5 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for cisco type 5 failed")
+	}
+
+	// Confirm enable secrets longer than the MD5 digest hash correctly.
+	passwdType5 := NewCiscoType5Passwd()
+	passwdType5.SetSalt([]byte("mERr"))
+	hashType5, err := passwdType5.HashPassword([]byte("ThisIsALongEnableSecret"))
+	if err != nil {
+		t.Fatalf("cisco type 5 long error: %s", err)
+	}
+	if string(hashType5) != "$1$mERr$bZFXVjOb2LrQtBEhiWRZX." {
+		t.Fatalf("cisco type 5 long hash is %s", hashType5)
+	}
+	res, err = CheckPassword(hashType5, []byte("ThisIsALongEnableSecret"))
+	if err != nil {
+		t.Fatalf("cisco type 5 long error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for cisco type 5 long failed")
+	}
+
+	res, err = CheckPassword([]byte("$8$abcdefghijklmn$cs4CuTfdvgmji136DVnccbdM6bE3dP6LoKJOK8VGDm6"), password)
+	if err != nil {
+		t.Fatalf("cisco type 8 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for cisco type 8 failed")
+	}
+
+	res, err = CheckPassword([]byte("$9$abcdefghijklmn$YAy/ttNvMvAT.mOcdLhQq4uESuSm9pUs4br7DIJdl3E"), password)
+	if err != nil {
+		t.Fatalf("cisco type 9 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for cisco type 9 failed")
+	}
+
+	res, err = NewLMPasswd().(PasswdChecker).CheckPassword([]byte("01FC5A6BE7BC6929AAD3B435B51404EE"), password)
+	if err != nil {
+		t.Fatalf("lm error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for lm failed")
+	}
+
+	// Confirm bare hex digests are not detected as LM hashes.
+	res, _ = CheckPassword([]byte("01FC5A6BE7BC6929AAD3B435B51404EE"), password)
+	if res {
+		t.Fatalf("Password check for bare hex matched as lm")
+	}
+
+	smb, err := ParseSMBPasswd("test:1000:01FC5A6BE7BC6929AAD3B435B51404EE:4A1FAB8F6B5441E0493DC7D41304BFB6:[U          ]:LCT-5B2C5F0D:")
+	if err != nil {
+		t.Fatalf("smbpasswd error: %s", err)
+	}
+	res, err = smb.CheckPassword(password)
+	if err != nil {
+		t.Fatalf("smbpasswd error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for smbpasswd failed")
+	}
+	res, err = NewLMPasswd().(PasswdChecker).CheckPassword(smb.LMHash, password)
+	if err != nil {
+		t.Fatalf("smbpasswd lm error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for smbpasswd lm failed")
+	}
+	if smb.String() != "test:1000:01FC5A6BE7BC6929AAD3B435B51404EE:4A1FAB8F6B5441E0493DC7D41304BFB6:[U          ]:LCT-5B2C5F0D:" {
+		t.Fatalf("smbpasswd line was not regenerated: %s", smb)
+	}
+
+	res, err = CheckPassword([]byte("{pbkdf2}000102030405060708090a0b0c0d0e0fcd95d99933074bb3fb388f3f3e478f648717efe7acabc976b28b777cb511bcaa"), password)
+	if err != nil {
+		t.Fatalf("spring pbkdf2 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for spring pbkdf2 failed")
+	}
+
+	res, err = CheckPassword([]byte("{pbkdf2}00010203040506074f32f00a3306c2a3302cd21b6959815b8667b5abc4ef98f236a6ca95581b19d4"), password)
+	if err != nil {
+		t.Fatalf("spring pbkdf2 sha1 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for spring pbkdf2 sha1 failed")
+	}
+
+	res, err = CheckPassword([]byte("{scrypt}$e0801$AAECAwQFBgcICQoLDA0ODw==$iQMFaeuwoJL/ztEGuYpgGLhII2fogSxIq4qd70+qpRo="), password)
+	if err != nil {
+		t.Fatalf("spring scrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for spring scrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("{scrypt}$e0801$AAECAwQFBgcICQoLDA0ODw==$iQMFaeuwoJL/ztEGuYpgGLhII2fogSxIq4qd70+qpRpX0V+qtukUbyydA+RRyYhja4/1xh2NOgeON7Tm7/ZOLw=="), password)
+	if err != nil {
+		t.Fatalf("spring scrypt 64 byte key error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for spring scrypt 64 byte key failed")
+	}
+
+	res, err = CheckPassword([]byte("{sha256}00010203040506078e3d9d1e4f05a0d012fc647c1c45d8d86b8bb83cc4bb97ff2dc4c2b09b1711a2"), password)
+	if err != nil {
+		t.Fatalf("spring sha256 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for spring sha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("{bcrypt}$2a$10$abcdefghijklmnopqrstuupYWJKQ4BNERAHkseHR6HFLBtBzDzsjO"), password)
+	if err != nil {
+		t.Fatalf("spring bcrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for spring bcrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("{noop}Test"), password)
+	if err != nil {
+		t.Fatalf("spring noop error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for spring noop failed")
+	}
+
+	res, err = CheckPassword([]byte("pbkdf2_sha256$600000$abcdefghijklmnopqrstuv$o/Sdbmmribgm4akt6x3KrnxoxPIXj0z719RKN3XdRD4="), password)
+	if err != nil {
+		t.Fatalf("django pbkdf2_sha256 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django pbkdf2_sha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("pbkdf2_sha1$600000$abcdefghijklmnopqrstuv$htyI3RATZnB/pvJE8Y1B4UzuYgg="), password)
+	if err != nil {
+		t.Fatalf("django pbkdf2_sha1 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django pbkdf2_sha1 failed")
+	}
+
+	res, err = CheckPassword([]byte("argon2$argon2id$v=19$m=102400,t=2,p=8$YWJjZGVmZ2hpamtsbW5vcHFyc3R1dg$5G8yLGvlR+5N5qlHIDdNnBmmYxHIXeH3/RYpzTKaq0I"), password)
+	if err != nil {
+		t.Fatalf("django argon2 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django argon2 failed")
+	}
+
+	res, err = CheckPassword([]byte("bcrypt_sha256$$2b$12$abcdefghijklmnopqrstuumdGlTmKcxvHBEEDv40qlwuIFABXmj3y"), password)
+	if err != nil {
+		t.Fatalf("django bcrypt_sha256 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django bcrypt_sha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("bcrypt$$2b$12$abcdefghijklmnopqrstuuGHGKGqeh4YYoLkTizi3qTKcwXcWh9fe"), password)
+	if err != nil {
+		t.Fatalf("django bcrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django bcrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("scrypt$16384$abcdefghijklmnopqrstuv$8$1$Yo4Op8ugZAUSK/LdUXJzNHEvzZbKz403kcDPVAjzG3/ld+Qk3xoPNsEXvSvQMZw4k4s88Oe9XM/JaC5XvrKJjA=="), password)
+	if err != nil {
+		t.Fatalf("django scrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django scrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("md5$abcdefghijklmnopqrstuv$5fcf4feab60865478c58827d42161783"), password)
+	if err != nil {
+		t.Fatalf("django md5 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django md5 failed")
+	}
+
+	res, err = CheckPassword([]byte("sha1$abcdefghijklmnopqrstuv$c841d5d72585f7bfb492c93d833b3e31e867623d"), password)
+	if err != nil {
+		t.Fatalf("django sha1 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django sha1 failed")
+	}
+
+	res, err = CheckPassword([]byte("md5$$0cbc6611f5540bd0809a388dc95a615b"), password)
+	if err != nil {
+		t.Fatalf("django unsalted md5 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django unsalted md5 failed")
+	}
+
+	res, err = CheckPassword([]byte("sha1$$640ab2bae07bedc4c163f679a746f7ab7fb5d1fa"), password)
+	if err != nil {
+		t.Fatalf("django unsalted sha1 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django unsalted sha1 failed")
+	}
+
+	res, err = CheckPassword([]byte("crypt$$ab.c/LGCUIB3s"), password)
+	if err != nil {
+		t.Fatalf("django crypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for django crypt failed")
+	}
+
+	res, err = CheckPassword([]byte("pbkdf2:sha256:600000$abcdefghijklmnop$8d1fe971b7e98343ac2eea052d2d234b0f56741526ecbd14354be60b5c656ea3"), password)
+	if err != nil {
+		t.Fatalf("werkzeug pbkdf2 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for werkzeug pbkdf2 failed")
+	}
+
+	res, err = CheckPassword([]byte("pbkdf2:sha512:1000$abcdefghijklmnop$9914f254e04f3ac18c310cb60c0096eb18bc7f7eeeeb1a8387e521c2846b5378553571852d8ba1dd3b6d0f7d4a6c519bec951402c0b85fb23f3549a7ec3a8ecd"), password)
+	if err != nil {
+		t.Fatalf("werkzeug pbkdf2 sha512 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for werkzeug pbkdf2 sha512 failed")
+	}
+
+	res, err = CheckPassword([]byte("scrypt:32768:8:1$abcdefghijklmnop$e234e88873a5780f4624515664a026cf71d61412a0e757ba934153b8266b2a9f83b1c34b029ca82b5c7208d033aa885371c5958a49ae63a624ebcce863f59bf1"), password)
+	if err != nil {
+		t.Fatalf("werkzeug scrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for werkzeug scrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("$bcrypt-sha256$v=2,t=2b,r=5$Wf1C3aGhd7kKf3dC1bG7nu$82L8fqwU9XFNPGKr8916swJ0iVa/F86"), password)
+	if err != nil {
+		t.Fatalf("bcrypt-sha256 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bcrypt-sha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("$bcrypt-sha256$2a,5$Wf1C3aGhd7kKf3dC1bG7nu$chIJAmW5QjcjtyQcchqVwR27OxJGvLy"), password)
+	if err != nil {
+		t.Fatalf("bcrypt-sha256 v1 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bcrypt-sha256 v1 failed")
+	}
+
+	// Confirm passwords longer than bcrypt's limit are not truncated.
+	res, err = CheckPassword([]byte("$bcrypt-sha256$v=2,t=2b,r=5$Wf1C3aGhd7kKf3dC1bG7nu$Z.F41zTYJxTznusjuHJIj2JYUXok5/m"), bytes.Repeat(password, 30))
+	if err != nil {
+		t.Fatalf("bcrypt-sha256 long error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for bcrypt-sha256 long failed")
+	}
+	res, err = CheckPassword([]byte("$bcrypt-sha256$v=2,t=2b,r=5$Wf1C3aGhd7kKf3dC1bG7nu$Z.F41zTYJxTznusjuHJIj2JYUXok5/m"), bytes.Repeat(password, 29))
+	if err != nil {
+		t.Fatalf("bcrypt-sha256 long error: %s", err)
+	}
+	if res {
+		t.Fatalf("Password check for bcrypt-sha256 long matched a shorter password")
+	}
+
+	firebase, err := NewFirebaseScryptPasswd("jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==", "Bw==", 8, 14)
+	if err != nil {
+		t.Fatalf("firebase scrypt error: %s", err)
+	}
+	firebase.SetSalt([]byte("42xEC+ixf3L2lw=="))
+	res, err = firebase.(PasswdChecker).CheckPassword([]byte("lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="), []byte("user1password"))
+	if err != nil {
+		t.Fatalf("firebase scrypt error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for firebase scrypt failed")
+	}
+
+	res, err = CheckPassword([]byte("0x01004A2B8C1DAB35F8CB0F7333334211F4A6CDD9AE3874D9239F"), password)
+	if err != nil {
+		t.Fatalf("mssql 2005 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for mssql 2005 failed")
+	}
+
+	res, err = CheckPassword([]byte("0x02004a2b8c1dc454c3b24951ed83ea9a66a472c6c2895d97a2880dce39667e876997ab7b53477c50fa77cbd315a8be2d82cc2ecc54096dd44cc2fcd8e5bc152467506b4e3510"), password)
+	if err != nil {
+		t.Fatalf("mssql 2012 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for mssql 2012 failed")
+	}
+
+	// Confirm the salt is taken from the hash, not the instance.
+	res, err = NewMSSQL2005Passwd().(PasswdChecker).CheckPassword([]byte("0x01004A2B8C1DAB35F8CB0F7333334211F4A6CDD9AE3874D9239F"), password)
+	if err != nil {
+		t.Fatalf("mssql 2005 instance error: %s", err)
 	}
 	if !res {
-		t.Fatalf("Password check for nt failed")
+		t.Fatalf("Password check for mssql 2005 instance failed")
 	}
 
-	res, err = CheckPassword([]byte("$5$AsETvlsIoaTP3w6G$OZY9mWRFXR9Pz0Xv1pS2TS/QCpxECLEG/dru/Y.nba/"), password)
+	res, err = CheckPassword([]byte("0x02004A2B8C1D563EA1B1CE8E5BB862362CF5ADC9FA6FC0B3FAAB14F1248F7902BFE4510B049FAE929F65D72C673D2C2026D8ED4BD679C2FC56678299D39DDB0DB46904E24BCF"), []byte("Tést€"))
 	if err != nil {
-		t.Fatalf("sha256 error: %s", err)
+		t.Fatalf("mssql 2012 unicode error: %s", err)
 	}
 	if !res {
-		t.Fatalf("Password check for sha256 failed")
+		t.Fatalf("Password check for mssql 2012 unicode failed")
 	}
 
-	res, err = CheckPassword([]byte("$5$rounds=243006$oCvhLw/Nn9HuQIm4$VPKzWx9t.NHgmNpVHeSpzQ5y01z4BE14J.bvG8g2yi."), password)
+	res, err = CheckPassword([]byte("S:A25AEB352D023665F81B9B6B467B3C08E13188AC1C2A3B4C5D6E7F8091A2"), password)
 	if err != nil {
-		t.Fatalf("sha256 with rounds error: %s", err)
+		t.Fatalf("oracle 11g error: %s", err)
 	}
 	if !res {
-		t.Fatalf("Password check for sha256 with rounds failed")
+		t.Fatalf("Password check for oracle 11g failed")
 	}
 
-	res, err = CheckPassword([]byte("$6$zt7D9I3Uu.EhrzEv$j50OCJ3oNdO2Ee7RE9XTDF7dhvrgRwc9NmjJUouk7czn4JTc/A6qLJIT1pMk7FUlTCYCLl6uBHm5NoEboAzIo0"), password)
+	res, err = CheckPassword([]byte("T:B9124980E21605C3873C88D441293D15591DFEF361FEB2BDCD9995D2E85FF50D891D7B34517E2009F51C280C361EB3F241597F827782259D24DEAAC1812B5D0D0123456789ABCDEFFEDCBA9876543210"), password)
 	if err != nil {
-		t.Fatalf("sha512 error: %s", err)
+		t.Fatalf("oracle 12c error: %s", err)
 	}
 	if !res {
-		t.Fatalf("Password check for sha512 failed")
+		t.Fatalf("Password check for oracle 12c failed")
 	}
 
-	res, err = CheckPassword([]byte("$6$rounds=523044$.zMtRwbPP2sDg5a5$YgKUnqEda6wxkvDMbJoNjNBiFNpX7nP/uDFV3jV4ngmrXlFBua3n8oIi5St/Re8H3WOksLaody3eAhaGtAN0c/"), password)
+	// Confirm the salt is taken from the verifier, not the instance.
+	res, err = NewOracle11gPasswd().(PasswdChecker).CheckPassword([]byte("S:A25AEB352D023665F81B9B6B467B3C08E13188AC1C2A3B4C5D6E7F8091A2"), password)
 	if err != nil {
-		t.Fatalf("sha512 with rounds error: %s", err)
+		t.Fatalf("oracle 11g instance error: %s", err)
 	}
 	if !res {
-		t.Fatalf("Password check for sha512 with rounds failed")
+		t.Fatalf("Password check for oracle 11g instance failed")
 	}
 
-	res, err = CheckPassword([]byte("$7$CU..../....PpL3ULxY5DvYyvasS/a4a0$jqgg90svZLt5KQqFTwegHSn1pXU.aKDavZ3Eq8t2wx9"), password)
+	res, err = NewOracle12cPasswd().(PasswdChecker).CheckPassword([]byte("T:B9124980E21605C3873C88D441293D15591DFEF361FEB2BDCD9995D2E85FF50D891D7B34517E2009F51C280C361EB3F241597F827782259D24DEAAC1812B5D0D0123456789ABCDEFFEDCBA9876543210"), password)
 	if err != nil {
-		t.Fatalf("scrypt error: %s", err)
+		t.Fatalf("oracle 12c instance error: %s", err)
 	}
 	if !res {
-		t.Fatalf("Password check for scrypt failed")
+		t.Fatalf("Password check for oracle 12c instance failed")
 	}
 
-	res, err = CheckPassword([]byte("$y$j9T$G/uoZu1orhwOE/lUtohEa.$SMu/wxtyhBLa5xeRLVnznBx5vE0/VxY7rJZlQX27N84"), password)
+	spare4, err := ParseOracleSpare4("S:A25AEB352D023665F81B9B6B467B3C08E13188AC1C2A3B4C5D6E7F8091A2;T:B9124980E21605C3873C88D441293D15591DFEF361FEB2BDCD9995D2E85FF50D891D7B34517E2009F51C280C361EB3F241597F827782259D24DEAAC1812B5D0D0123456789ABCDEFFEDCBA9876543210;H:DC9894A01797D91D92ECA1DA66242209")
 	if err != nil {
-		t.Fatalf("yes crypt error: %s", err)
+		t.Fatalf("oracle spare4 error: %s", err)
+	}
+	if types := strings.Join(spare4.Types(), ","); types != "S,T,H" {
+		t.Fatalf("oracle spare4 types are %s", types)
+	}
+	res, err = spare4.CheckPassword(password)
+	if err != nil {
+		t.Fatalf("oracle spare4 error: %s", err)
 	}
 	if !res {
-		t.Fatalf("Password check for yes crypt failed")
+		t.Fatalf("Password check for oracle spare4 failed")
 	}
 
-	res, err = CheckPassword([]byte("$gy$j9T$etkZHzB483TIuw/58Df.N/$7DjHx/8jx.E/VLdyzMIIOJULHoZJ1PNlFl71KXaf0s7"), password)
+	// Confirm Kerberos string-to-key matches the RFC 3962 test vectors.
+	for _, v := range []struct {
+		encType    uint16
+		iterations int
+		key        string
+	}{
+		{KERBEROS_AES128_CTS_HMAC_SHA1_96, 1, "42263c6e89f4fc28b8df68ee09799f15"},
+		{KERBEROS_AES256_CTS_HMAC_SHA1_96, 1, "fe697b52bc0d3ce14432ba036a92e65bbb52280990a2fa27883998d72af30161"},
+		{KERBEROS_AES128_CTS_HMAC_SHA1_96, 1200, "4c01cd46d632d01e6dbe230a01ed642a"},
+		{KERBEROS_AES256_CTS_HMAC_SHA1_96, 1200, "55a6ac740ad17b4846941051e1e8b0a7548d93b0ab30a8bc3ff16280382b8c2a"},
+	} {
+		key, err := KerberosStringToKey(v.encType, []byte("password"), []byte("ATHENA.MIT.EDUraeburn"), v.iterations)
+		if err != nil {
+			t.Fatalf("kerberos %d error: %s", v.encType, err)
+		}
+		if hex.EncodeToString(key) != v.key {
+			t.Fatalf("kerberos %d key is %x", v.encType, key)
+		}
+	}
+
+	// Confirm the keytab is written in the MIT format, with the RC4 key being the NT hash.
+	principal, err := ParseKerberosPrincipal("HTTP/www.example.com@EXAMPLE.COM")
 	if err != nil {
-		t.Fatalf("gost yes crypt error: %s", err)
+		t.Fatalf("kerberos principal error: %s", err)
+	}
+	if string(principal.Salt()) != "EXAMPLE.COMHTTPwww.example.com" {
+		t.Fatalf("kerberos principal salt is %s", principal.Salt())
+	}
+	var keytab KerberosKeytab
+	err = keytab.AddPassword(principal, password, 3, KERBEROS_RC4_HMAC)
+	if err != nil {
+		t.Fatalf("kerberos keytab error: %s", err)
+	}
+	keytab.Entries[0].Timestamp = time.Unix(0x01020304, 0)
+	var keytabData bytes.Buffer
+	_, err = keytab.WriteTo(&keytabData)
+	if err != nil {
+		t.Fatalf("kerberos keytab error: %s", err)
+	}
+	if hex.EncodeToString(keytabData.Bytes()) != "0502000000470002000b4558414d504c452e434f4d000448545450000f7777772e6578616d706c652e636f6d000000010102030403001700104a1fab8f6b5441e0493dc7d41304bfb600000003" {
+		t.Fatalf("kerberos keytab is %x", keytabData.Bytes())
+	}
+
+	// Confirm lower case LDAP salted SHA hashes are not taken as AIX hashes.
+	res, err = CheckPassword([]byte("{ssha256}rF4FeqgcgEvbTu/fTnfQZzvvGSg2OiVpaK8YJ8O9oV1zYWx0c2FsdA=="), password)
+	if err != nil {
+		t.Fatalf("ldap lower case ssha256 error: %s", err)
 	}
 	if !res {
-		t.Fatalf("Password check for gost yes crypt failed")
+		t.Fatalf("Password check for ldap lower case ssha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("{ssha1}06$bJbkFGJAB30L2e23$dCESGOsP7jaIIAJ1QAcmaGeG.kr"), []byte("hashcat"))
+	if err != nil {
+		t.Fatalf("aix ssha1 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for aix ssha1 failed")
+	}
+
+	res, err = CheckPassword([]byte("{ssha256}06$aJckFGJAB30LTe10$ohUsB7LBPlgclE3hJg9x042DLJvQyxVCX.nZZLEz.g2"), []byte("hashcat"))
+	if err != nil {
+		t.Fatalf("aix ssha256 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for aix ssha256 failed")
+	}
+
+	res, err = CheckPassword([]byte("{ssha512}06$bJbkFGJAB30L2e23$bXiXjyH5YGIyoWWmEVwq67nCU5t7GLy9HkCzrodRCQCx3r9VvG98o7O3V0r9cVrX3LPPGuHqT5LLn0oGCuI1.."), []byte("hashcat"))
+	if err != nil {
+		t.Fatalf("aix ssha512 error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for aix ssha512 failed")
+	}
+
+	// Confirm yescrypt params are decoded and encoded without loss.
+	for _, p := range []string{"j9T", "j9T..", "j9T/.", "j9T0./", "jD5.7", "/9T", ".9T", "jFU7.5"} {
+		params, err := DecodeYescryptParams(p)
+		if err != nil {
+			t.Fatalf("yescrypt params %s error: %s", p, err)
+		}
+		encoded, err := params.Encode()
+		if err != nil {
+			t.Fatalf("yescrypt params %s error: %s", p, err)
+		}
+		if encoded != p {
+			t.Fatalf("yescrypt params %s encoded as %s", p, encoded)
+		}
+	}
+	params, err := DecodeYescryptParams("j9T/.")
+	if err != nil {
+		t.Fatalf("yescrypt params error: %s", err)
+	}
+	if params.Flags != YESCRYPT_DEFAULTS || params.N != 4096 || params.R != 32 || params.P != 1 || params.T != 1 {
+		t.Fatalf("yescrypt params decoded incorrectly: %+v", params)
+	}
+
+	for _, h := range []string{
+		"$y$j9T/.$G/uoZu1orhwOE/lUtohEa.$pPdJVx8PfpqSG.5hidyW43QWnaW847.ub2Z8fI7sqd.",
+		"$y$j9T..$G/uoZu1orhwOE/lUtohEa.$.8SzrrM3yGfJZqMTuQjTzbUBf.gwq.oUC9uQ3m8r8d/",
+		"$y$/9T$G/uoZu1orhwOE/lUtohEa.$rTfNBJU4vhhwrKBZCpMKJHVC5Ouzj9w/FIG.AxXyje2",
+		"$y$.9T$G/uoZu1orhwOE/lUtohEa.$5MNAoaTxN9BhHybmdRHEJshldvOKgMyilnIp9x7Jto7",
+	} {
+		res, err = CheckPassword([]byte(h), password)
+		if err != nil {
+			t.Fatalf("yescrypt %s error: %s", h, err)
+		}
+		if !res {
+			t.Fatalf("Password check for yescrypt %s failed", h)
+		}
+	}
+
+	// Confirm yescrypt hashes with a ROM require it, and verify with it.
+	rom, err := NewYescryptROM([]byte("test rom"), 1024, 8)
+	if err != nil {
+		t.Fatalf("yescrypt rom error: %s", err)
+	}
+	yes := NewYesCryptPasswd().(*YesCrypt)
+	params = DefaultYescryptParams()
+	params.N = 2048
+	err = yes.SetYescryptParams(rom.Params(params))
+	if err != nil {
+		t.Fatalf("yescrypt rom params error: %s", err)
+	}
+	_, err = yes.HashPassword(password)
+	if err == nil {
+		t.Fatalf("yescrypt rom hash without a rom did not error")
+	}
+	yes.SetROM(rom)
+	romHash, err := yes.HashPassword(password)
+	if err != nil {
+		t.Fatalf("yescrypt rom hash error: %s", err)
+	}
+	var romData bytes.Buffer
+	_, err = rom.WriteTo(&romData)
+	if err != nil {
+		t.Fatalf("yescrypt rom write error: %s", err)
+	}
+	rom, err = ReadYescryptROM(&romData, 8)
+	if err != nil {
+		t.Fatalf("yescrypt rom read error: %s", err)
+	}
+	SetDefaultYescryptROM(rom)
+	res, err = CheckPassword(romHash, password)
+	SetDefaultYescryptROM(nil)
+	if err != nil {
+		t.Fatalf("yescrypt rom error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for yescrypt rom failed")
 	}
 
 	// Confirm new password generation works.
@@ -125,6 +1027,13 @@ func TestPasswd(t *testing.T) {
 	}
 	fmt.Println("sun md5:", string(hash))
 
+	passwd = NewAPR1Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("apr1 error: %s", err)
+	}
+	fmt.Println("apr1:", string(hash))
+
 	passwd = NewSHA256CryptPasswd()
 	hash, err = passwd.HashPassword(password)
 	if err != nil {
@@ -159,4 +1068,317 @@ func TestPasswd(t *testing.T) {
 		t.Fatalf("gost yes crypterror: %s", err)
 	}
 	fmt.Println("gost yes crypt:", string(hash))
+
+	passwd = NewBcryptPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("bcrypt error: %s", err)
+	}
+	fmt.Println("bcrypt:", string(hash))
+
+	passwd = NewDESCryptPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("des error: %s", err)
+	}
+	fmt.Println("des:", string(hash))
+
+	passwd = NewBSDiCryptPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("bsdi error: %s", err)
+	}
+	fmt.Println("bsdi:", string(hash))
+
+	passwd = NewBigCryptPasswd()
+	hash, err = passwd.HashPassword([]byte("Testpassword12345"))
+	if err != nil {
+		t.Fatalf("bigcrypt error: %s", err)
+	}
+	fmt.Println("bigcrypt:", string(hash))
+
+	passwd = NewCrypt16Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("crypt16 error: %s", err)
+	}
+	fmt.Println("crypt16:", string(hash))
+
+	passwd = NewArgon2idPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("argon2id error: %s", err)
+	}
+	fmt.Println("argon2id:", string(hash))
+
+	passwd = NewPBKDF2SHA256Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("pbkdf2 sha256 error: %s", err)
+	}
+	fmt.Println("pbkdf2 sha256:", string(hash))
+
+	passwd = NewPHPassPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("phpass error: %s", err)
+	}
+	fmt.Println("phpass:", string(hash))
+
+	passwd = NewDrupalPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("drupal error: %s", err)
+	}
+	fmt.Println("drupal:", string(hash))
+
+	passwd = NewMySQLNativePasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("mysql native error: %s", err)
+	}
+	fmt.Println("mysql native:", string(hash))
+
+	passwd = NewMySQLCachingSHA2Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("mysql caching sha2 error: %s", err)
+	}
+	fmt.Printf("mysql caching sha2: %q\n", hash)
+
+	passwd = NewPostgresSCRAMPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("postgres scram error: %s", err)
+	}
+	fmt.Println("postgres scram:", string(hash))
+
+	passwd, err = NewLDAPPasswd("SSHA")
+	if err != nil {
+		t.Fatalf("ldap ssha error: %s", err)
+	}
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("ldap ssha error: %s", err)
+	}
+	fmt.Println("ldap ssha:", string(hash))
+
+	passwd = NewLDAPCryptPasswd(NewSHA512CryptPasswd())
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("ldap crypt error: %s", err)
+	}
+	fmt.Println("ldap crypt:", string(hash))
+
+	passwd = NewCiscoType5Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("cisco type 5 error: %s", err)
+	}
+	fmt.Println("cisco type 5:", string(hash))
+
+	passwd = NewCiscoType8Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("cisco type 8 error: %s", err)
+	}
+	fmt.Println("cisco type 8:", string(hash))
+
+	passwd = NewCiscoType9Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("cisco type 9 error: %s", err)
+	}
+	fmt.Println("cisco type 9:", string(hash))
+
+	passwd = NewLMPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("lm error: %s", err)
+	}
+	fmt.Println("lm:", string(hash))
+
+	err = smb.SetPassword([]byte("Test2"))
+	if err != nil {
+		t.Fatalf("smbpasswd error: %s", err)
+	}
+	res, err = smb.CheckPassword([]byte("Test2"))
+	if err != nil {
+		t.Fatalf("smbpasswd error: %s", err)
+	}
+	if !res {
+		t.Fatalf("Password check for new smbpasswd failed")
+	}
+	if smb.LMHash != nil {
+		t.Fatalf("smbpasswd set an LM hash without it being requested")
+	}
+	err = smb.SetPasswordWithLM([]byte("ThisIsLongerThan14"))
+	if err != nil {
+		t.Fatalf("smbpasswd error: %s", err)
+	}
+	if smb.LMHash != nil {
+		t.Fatalf("smbpasswd set an LM hash for a password longer than 14 characters")
+	}
+	err = smb.SetPasswordWithLM([]byte("Test2"))
+	if err != nil {
+		t.Fatalf("smbpasswd error: %s", err)
+	}
+	res, err = smb.CheckPassword([]byte("Test2"))
+	if err != nil {
+		t.Fatalf("smbpasswd error: %s", err)
+	}
+	if !res || smb.LMHash == nil {
+		t.Fatalf("Password check for new smbpasswd with lm failed")
+	}
+	fmt.Println("smbpasswd:", smb.String())
+
+	passwd = NewSpringBcryptPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("spring bcrypt error: %s", err)
+	}
+	fmt.Println("spring bcrypt:", string(hash))
+
+	passwd = NewSpringPBKDF2Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("spring pbkdf2 error: %s", err)
+	}
+	fmt.Println("spring pbkdf2:", string(hash))
+
+	passwd = NewSpringSCryptPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("spring scrypt error: %s", err)
+	}
+	fmt.Println("spring scrypt:", string(hash))
+
+	passwd = NewSpringSHA256Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("spring sha256 error: %s", err)
+	}
+	fmt.Println("spring sha256:", string(hash))
+
+	passwd = NewSpringNoopPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("spring noop error: %s", err)
+	}
+	fmt.Println("spring noop:", string(hash))
+
+	passwd = NewDjangoPBKDF2SHA256Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("django pbkdf2_sha256 error: %s", err)
+	}
+	fmt.Println("django pbkdf2_sha256:", string(hash))
+
+	passwd = NewDjangoArgon2Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("django argon2 error: %s", err)
+	}
+	fmt.Println("django argon2:", string(hash))
+
+	passwd = NewDjangoBcryptSHA256Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("django bcrypt_sha256 error: %s", err)
+	}
+	fmt.Println("django bcrypt_sha256:", string(hash))
+
+	passwd = NewDjangoSCryptPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("django scrypt error: %s", err)
+	}
+	fmt.Println("django scrypt:", string(hash))
+
+	passwd = NewDjangoMD5Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("django md5 error: %s", err)
+	}
+	fmt.Println("django md5:", string(hash))
+
+	passwd = NewDjangoUnsaltedSHA1Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("django unsalted sha1 error: %s", err)
+	}
+	fmt.Println("django unsalted sha1:", string(hash))
+
+	passwd = NewDjangoCryptPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("django crypt error: %s", err)
+	}
+	fmt.Println("django crypt:", string(hash))
+
+	passwd = NewWerkzeugPBKDF2Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("werkzeug pbkdf2 error: %s", err)
+	}
+	fmt.Println("werkzeug pbkdf2:", string(hash))
+
+	passwd = NewWerkzeugSCryptPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("werkzeug scrypt error: %s", err)
+	}
+	fmt.Println("werkzeug scrypt:", string(hash))
+
+	passwd = NewBcryptSHA256Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("bcrypt-sha256 error: %s", err)
+	}
+	fmt.Println("bcrypt-sha256:", string(hash))
+
+	passwd, err = NewFirebaseScryptPasswd("jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==", "Bw==", 8, 14)
+	if err != nil {
+		t.Fatalf("firebase scrypt error: %s", err)
+	}
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("firebase scrypt error: %s", err)
+	}
+	fmt.Println("firebase scrypt:", string(hash))
+
+	passwd = NewMSSQL2005Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("mssql 2005 error: %s", err)
+	}
+	fmt.Println("mssql 2005:", string(hash))
+
+	passwd = NewMSSQL2012Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("mssql 2012 error: %s", err)
+	}
+	fmt.Println("mssql 2012:", string(hash))
+
+	passwd = NewOracle11gPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("oracle 11g error: %s", err)
+	}
+	fmt.Println("oracle 11g:", string(hash))
+
+	passwd = NewOracle12cPasswd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("oracle 12c error: %s", err)
+	}
+	fmt.Println("oracle 12c:", string(hash))
+
+	passwd = NewAIXSSHA512Passwd()
+	hash, err = passwd.HashPassword(password)
+	if err != nil {
+		t.Fatalf("aix ssha512 error: %s", err)
+	}
+	fmt.Println("aix ssha512:", string(hash))
 }
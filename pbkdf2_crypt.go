@@ -0,0 +1,102 @@
+package passwd
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+type PBKDF2Crypt struct {
+	Passwd
+}
+
+// Make a PBKDF2 with SHA1 password instance.
+func NewPBKDF2SHA1Passwd() PasswdInterface {
+	return newPBKDF2Passwd(PBKDF2_SHA1_MAGIC, "131000")
+}
+
+// Make a PBKDF2 with SHA256 password instance.
+func NewPBKDF2SHA256Passwd() PasswdInterface {
+	return newPBKDF2Passwd(PBKDF2_SHA256_MAGIC, "29000")
+}
+
+// Make a PBKDF2 with SHA512 password instance.
+func NewPBKDF2SHA512Passwd() PasswdInterface {
+	return newPBKDF2Passwd(PBKDF2_SHA512_MAGIC, "25000")
+}
+
+// Make a PBKDF2 password instance with the default iterations for the magic.
+func newPBKDF2Passwd(magic string, iterations string) PasswdInterface {
+	m := new(PBKDF2Crypt)
+	m.Magic = magic
+	m.Params = iterations
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Get the hash function and digest size for the magic.
+func (a *PBKDF2Crypt) hashFunc() (h func() hash.Hash, size int, err error) {
+	switch a.Magic {
+	case PBKDF2_SHA1_MAGIC:
+		h, size = sha1.New, SHA1_SIZE
+	case PBKDF2_SHA256_MAGIC:
+		h, size = sha256.New, SHA256_SIZE
+	case PBKDF2_SHA512_MAGIC:
+		h, size = sha512.New, SHA512_SIZE
+	default:
+		err = errors.New("unsupported PBKDF2 hash")
+	}
+	return
+}
+
+// Generate a salt encoded with the adapted base64 alphabet.
+func (a *PBKDF2Crypt) GenerateSalt() ([]byte, error) {
+	rawSalt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	return AB64Encode(rawSalt), nil
+}
+
+// Hash a password with salt using PBKDF2 in the passlib format.
+func (a *PBKDF2Crypt) Hash(password []byte, salt []byte, iterations uint64) (hash []byte, err error) {
+	h, size, err := a.hashFunc()
+	if err != nil {
+		return
+	}
+	if iterations < 1 {
+		err = errors.New("PBKDF2 iterations must be greater than 0")
+		return
+	}
+
+	// The salt is stored encoded, so decode it to the raw bytes.
+	rawSalt, err := AB64Decode(salt)
+	if err != nil {
+		return
+	}
+	key := pbkdf2.Key(password, rawSalt, int(iterations), size, h)
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%d$%s$", a.Magic, iterations, salt))
+	hash = append(hash, AB64Encode(key)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with PBKDF2.
+func (a *PBKDF2Crypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	iterations, err := strconv.ParseUint(a.Params, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err = a.Hash(password, salt, iterations)
+	return
+}
@@ -0,0 +1,126 @@
+package passwd
+
+import (
+	"crypto/md5"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+type PHPass struct {
+	Passwd
+}
+
+// Make a phpass portable password instance, as used by WordPress.
+func NewPHPassPasswd() PasswdInterface {
+	return newPHPassPasswd(PHPASS_MAGIC, 13)
+}
+
+// Make a phpass portable password instance with the phpBB magic.
+func NewPHPassHPasswd() PasswdInterface {
+	return newPHPassPasswd(PHPASS_H_MAGIC, 13)
+}
+
+// Make a Drupal 7 SHA512 password instance.
+func NewDrupalPasswd() PasswdInterface {
+	return newPHPassPasswd(DRUPAL_SHA512_MAGIC, 15)
+}
+
+// Make a phpass password instance with the default log2 iterations.
+func newPHPassPasswd(magic string, log2Iterations int) *PHPass {
+	m := new(PHPass)
+	m.Magic = magic
+	m.SetLog2Iterations(log2Iterations)
+	// The salt is 8 characters.
+	m.SaltLength = 8
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Sets the base 2 logarithm of the iterations, which is between 7 and 30.
+func (a *PHPass) SetLog2Iterations(log2Iterations int) (err error) {
+	if log2Iterations < 7 || log2Iterations > 30 {
+		return errors.New("phpass log2 iterations must be between 7 and 30")
+	}
+	a.Params = string(iota64Encoding[log2Iterations])
+	return
+}
+
+// Decode the base 2 logarithm of the iterations.
+func (a *PHPass) DecodeLog2Iterations() (log2Iterations int) {
+	b64 := []byte(a.Params)
+	if len(b64) != 1 {
+		return
+	}
+	return AToI64(b64[0])
+}
+
+// Get the hash function for the magic. Drupal replaced MD5 with SHA512,
+// but otherwise uses the same algorithm.
+func (a *PHPass) hashFunc() (h func() hash.Hash, err error) {
+	switch a.Magic {
+	case PHPASS_MAGIC, PHPASS_H_MAGIC:
+		h = md5.New
+	case DRUPAL_SHA512_MAGIC:
+		h = sha512.New
+	default:
+		err = errors.New("unsupported phpass variant")
+	}
+	return
+}
+
+// Generate an 8 character salt.
+func (a *PHPass) GenerateSalt() ([]byte, error) {
+	return generateIota64Salt(uint(a.SaltLength))
+}
+
+// Hash a password with salt using the phpass portable standard.
+func (a *PHPass) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	hashFunc, err := a.hashFunc()
+	if err != nil {
+		return
+	}
+	h := hashFunc()
+
+	log2Iterations := a.DecodeLog2Iterations()
+	if log2Iterations < 7 || log2Iterations > 30 {
+		err = errors.New("phpass log2 iterations must be between 7 and 30")
+		return
+	}
+
+	// Salt should be 8 characters.
+	if len(salt) < 8 {
+		err = errors.New("phpass salt must be 8 characters")
+		return
+	}
+	salt = salt[:8]
+
+	// Hash the salt and password, then rehash with the password for each iteration.
+	h.Write(salt)
+	h.Write(password)
+	result := h.Sum(nil)
+	for cnt := 1 << log2Iterations; cnt > 0; cnt-- {
+		h.Reset()
+		h.Write(result)
+		h.Write(password)
+		result = h.Sum(nil)
+	}
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%s%s", a.Magic, a.Params, salt))
+	hash = append(hash, SCryptBase64Encode(result)...)
+
+	// Drupal truncates hashes to 55 characters.
+	if len(hash) > 55 {
+		hash = hash[:55]
+	}
+	return
+}
+
+// Override the passwd hash with salt function to hash with phpass.
+func (a *PHPass) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
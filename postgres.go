@@ -0,0 +1,123 @@
+package passwd
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+type PostgresSCRAM struct {
+	Passwd
+}
+
+type PostgresMD5 struct {
+	Passwd
+}
+
+// Make a PostgreSQL SCRAM-SHA-256 verifier instance.
+func NewPostgresSCRAMPasswd() PasswdInterface {
+	m := new(PostgresSCRAM)
+	m.Magic = POSTGRES_SCRAM_MAGIC
+	m.Params = "4096"
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a PostgreSQL md5 password instance. The username is used as the salt,
+// so it must be set with SetSalt before hashing.
+func NewPostgresMD5Passwd() PasswdInterface {
+	m := new(PostgresMD5)
+	m.Magic = POSTGRES_MD5_MAGIC
+	// The salt is the username, so it can not be generated.
+	m.SaltLength = -1
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Generate a salt encoded with standard base64.
+func (a *PostgresSCRAM) GenerateSalt() ([]byte, error) {
+	rawSalt, err := generateRandomBytes(uint(a.SaltLength))
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, base64.StdEncoding.EncodedLen(len(rawSalt)))
+	base64.StdEncoding.Encode(salt, rawSalt)
+	return salt, nil
+}
+
+// Hash a password with salt to a SCRAM-SHA-256 verifier. PostgreSQL normalizes
+// passwords with SASLprep, which is not applied here, so non-ASCII passwords
+// must be normalized by the caller.
+func (a *PostgresSCRAM) Hash(password []byte, salt []byte, iterations uint64) (hash []byte, err error) {
+	if iterations < 1 {
+		err = errors.New("SCRAM iterations must be greater than 0")
+		return
+	}
+	rawSalt, err := base64.StdEncoding.DecodeString(string(salt))
+	if err != nil {
+		return
+	}
+
+	// Derive the keys from the salted password.
+	saltedPassword := pbkdf2.Key(password, rawSalt, int(iterations), SHA256_SIZE, sha256.New)
+	hm := hmac.New(sha256.New, saltedPassword)
+	hm.Write([]byte("Client Key"))
+	clientKey := hm.Sum(nil)
+	storedKey := sha256.Sum256(clientKey)
+	hm = hmac.New(sha256.New, saltedPassword)
+	hm.Write([]byte("Server Key"))
+	serverKey := hm.Sum(nil)
+
+	// Create verifier with result.
+	hash = []byte(fmt.Sprintf("%s%d:%s$%s:%s", a.Magic, iterations, salt,
+		base64.StdEncoding.EncodeToString(storedKey[:]), base64.StdEncoding.EncodeToString(serverKey)))
+	return
+}
+
+// Override the passwd hash with salt function to hash with SCRAM-SHA-256.
+func (a *PostgresSCRAM) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	iterations, err := strconv.ParseUint(a.Params, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err = a.Hash(password, salt, iterations)
+	return
+}
+
+// The username is required as the salt, so it can not be generated.
+func (a *PostgresMD5) GenerateSalt() ([]byte, error) {
+	return nil, errors.New("PostgreSQL md5 requires the username as the salt")
+}
+
+// Hash a password with the username using the PostgreSQL md5 standard.
+func (a *PostgresMD5) Hash(password []byte, username []byte) (hash []byte) {
+	h := md5.New()
+	h.Write(password)
+	h.Write(username)
+	buf := h.Sum(nil)
+
+	// Hex encode MD5 hash.
+	dst := make([]byte, hex.EncodedLen(len(buf)))
+	hex.Encode(dst, buf)
+
+	// Make hash with magic.
+	hash = append([]byte(a.Magic), dst...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with PostgreSQL md5, using the salt as the username.
+func (a *PostgresMD5) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash = a.Hash(password, salt)
+	return
+}
@@ -0,0 +1,43 @@
+package passwd
+
+import "bytes"
+
+// Wraps another password instance, prefixing the magic to its hashes.
+// Used by formats which only add a scheme to an existing hash.
+type PrefixPasswd struct {
+	PasswdInterface
+	Magic string
+}
+
+// Make a password instance which prefixes the magic to the wrapped hashes.
+func NewPrefixPasswd(magic string, crypt PasswdInterface) PasswdInterface {
+	return &PrefixPasswd{PasswdInterface: crypt, Magic: magic}
+}
+
+// Hash a password with the wrapped crypt, and prefix the magic.
+func (a *PrefixPasswd) HashPassword(password []byte) (hash []byte, err error) {
+	hash, err = a.PasswdInterface.HashPassword(password)
+	if err != nil {
+		return
+	}
+	hash = append([]byte(a.Magic), hash...)
+	return
+}
+
+// Hash a password and salt with the wrapped crypt, and prefix the magic.
+func (a *PrefixPasswd) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.PasswdInterface.HashPasswordWithSalt(password, salt)
+	if err != nil {
+		return
+	}
+	hash = append([]byte(a.Magic), hash...)
+	return
+}
+
+// Check a password against the wrapped hash, after the magic.
+func (a *PrefixPasswd) CheckPassword(hash []byte, password []byte) (bool, error) {
+	if !bytes.HasPrefix(hash, []byte(a.Magic)) {
+		return false, nil
+	}
+	return CheckPassword(hash[len(a.Magic):], password)
+}
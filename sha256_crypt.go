@@ -25,15 +25,33 @@ func (a *SHA256Crypt) Hash(password []byte, salt []byte, iterations uint64) (has
 		salt = salt[0:16]
 	}
 
-	passwordLen := len(password)
-	saltLen := len(salt)
-
 	customIterations := true
 	if iterations == 0 {
 		customIterations = false
 		iterations = 5000
 	}
 
+	// Compute the digest.
+	result := sha256CryptSum(password, salt, iterations)
+
+	output := fmt.Sprintf("%s%s$", a.Magic, salt)
+	if customIterations {
+		output = fmt.Sprintf("%srounds=%d$%s$", a.Magic, iterations, salt)
+	}
+
+	// Create hash with result.
+	b64 := Base64RotateEncode(result, false)
+	hash = []byte(output)
+	hash = append(hash, b64...)
+	return
+}
+
+// Compute the SHA256 crypt digest, which is shared with MySQL caching_sha2_password.
+// Unlike the crypt standard, the salt is not limited to 16 characters here.
+func sha256CryptSum(password []byte, salt []byte, iterations uint64) []byte {
+	passwordLen := len(password)
+	saltLen := len(salt)
+
 	// Encode pass, salt, pass hash to feed into the next hash.
 	h := sha256.New()
 	h.Write(password)
@@ -111,17 +129,7 @@ func (a *SHA256Crypt) Hash(password []byte, salt []byte, iterations uint64) (has
 		// Compute hash for next round.
 		result = h.Sum(nil)
 	}
-
-	output := fmt.Sprintf("%s%s$", a.Magic, salt)
-	if customIterations {
-		output = fmt.Sprintf("%srounds=%d$%s$", a.Magic, iterations, salt)
-	}
-
-	// Create hash with result.
-	b64 := Base64RotateEncode(result, false)
-	hash = []byte(output)
-	hash = append(hash, b64...)
-	return
+	return result
 }
 
 // Override the passwd hash with salt function to hash with SHA256 crypt.
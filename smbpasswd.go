@@ -0,0 +1,143 @@
+package passwd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A record from a Samba smbpasswd file.
+//
+//	<username>:<uid>:<lm hash>:<nt hash>:[<flags>]:LCT-<last change>:
+//
+// The NT hash is stored in the NT crypt format, so it may be passed directly
+// to CheckPassword. The LM hash is bare hex, which is not detected as LM, so
+// it must be checked with an instance from NewLMPasswd. A nil hash means it
+// is disabled or unset.
+type SMBPasswd struct {
+	Username string
+	UID      int
+	LMHash   []byte
+	NTHash   []byte
+	// Account flags without the brackets, such as "U" for a user account.
+	Flags string
+	// The last change time, zero if not set.
+	LastChange time.Time
+}
+
+// Placeholders for hashes which are unset, or set to no password.
+const (
+	smbpasswdDisabled   = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+	smbpasswdNoPassword = "NO PASSWORDXXXXXXXXXXXXXXXXXXXXX"
+)
+
+// Parse a line from an smbpasswd file.
+func ParseSMBPasswd(line string) (*SMBPasswd, error) {
+	s := strings.Split(strings.TrimRight(line, "\r\n"), ":")
+	if len(s) < 4 {
+		return nil, errors.New("Too few fields for smbpasswd line")
+	}
+
+	uid, err := strconv.Atoi(s[1])
+	if err != nil {
+		return nil, err
+	}
+	e := &SMBPasswd{Username: s[0], UID: uid}
+
+	// Parse the LM hash.
+	if len(s[2]) != 32 {
+		return nil, errors.New("Invalid LM hash length in smbpasswd line")
+	}
+	if s[2] != smbpasswdDisabled && s[2] != smbpasswdNoPassword {
+		if _, err := hex.DecodeString(s[2]); err != nil {
+			return nil, err
+		}
+		e.LMHash = []byte(s[2])
+	}
+
+	// Parse the NT hash, which is converted to the NT crypt format.
+	if len(s[3]) != 32 {
+		return nil, errors.New("Invalid NT hash length in smbpasswd line")
+	}
+	if s[3] != smbpasswdDisabled && s[3] != smbpasswdNoPassword {
+		e.NTHash = []byte(NT_HASH_MAGIC + "$" + strings.ToLower(s[3]))
+		if _, err := NewPasswd(string(e.NTHash)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Old format lines have no flags or last change time.
+	if len(s) > 4 && strings.HasPrefix(s[4], "[") && strings.HasSuffix(s[4], "]") {
+		e.Flags = strings.TrimSpace(s[4][1 : len(s[4])-1])
+
+		if len(s) > 5 && strings.HasPrefix(s[5], "LCT-") {
+			lct, err := strconv.ParseInt(s[5][4:], 16, 64)
+			if err != nil {
+				return nil, err
+			}
+			e.LastChange = time.Unix(lct, 0)
+		}
+	}
+	return e, nil
+}
+
+// Check a password, preferring the NT hash over the LM hash.
+func (e *SMBPasswd) CheckPassword(password []byte) (bool, error) {
+	if e.NTHash != nil {
+		return CheckPassword(e.NTHash, password)
+	}
+	if e.LMHash != nil {
+		return NewLMPasswd().(PasswdChecker).CheckPassword(e.LMHash, password)
+	}
+	return false, errors.New("No password hash in smbpasswd record")
+}
+
+// Set a new password, which updates the NT hash and the last change time.
+// The LM hash is cleared, as Samba defaults to not using LM authentication.
+func (e *SMBPasswd) SetPassword(password []byte) (err error) {
+	e.NTHash, err = NewNTPasswd().HashPassword(password)
+	if err != nil {
+		return
+	}
+	e.LMHash = nil
+	e.LastChange = time.Now()
+	return
+}
+
+// Set a new password, also writing the LM hash for clients which require LM
+// authentication. Like Samba, passwords longer than 14 characters have no LM
+// hash, as it would only be of a truncated password.
+func (e *SMBPasswd) SetPasswordWithLM(password []byte) (err error) {
+	err = e.SetPassword(password)
+	if err != nil || len(password) > 14 {
+		return
+	}
+	e.LMHash, err = NewLMPasswd().HashPassword(password)
+	return
+}
+
+// Format the record as an smbpasswd line.
+func (e *SMBPasswd) String() string {
+	// Accounts with the no password flag use a different placeholder.
+	unset := smbpasswdDisabled
+	if strings.Contains(e.Flags, "N") {
+		unset = smbpasswdNoPassword
+	}
+
+	lm := unset
+	if e.LMHash != nil {
+		lm = strings.ToUpper(string(e.LMHash))
+	}
+	nt := unset
+	if e.NTHash != nil {
+		nt = strings.ToUpper(strings.TrimPrefix(string(e.NTHash), NT_HASH_MAGIC+"$"))
+	}
+	lct := ""
+	if !e.LastChange.IsZero() {
+		lct = fmt.Sprintf("LCT-%08X", e.LastChange.Unix())
+	}
+	return fmt.Sprintf("%s:%d:%s:%s:[%-11s]:%s:", e.Username, e.UID, lm, nt, e.Flags, lct)
+}
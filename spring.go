@@ -0,0 +1,234 @@
+package passwd
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+
+	"github.com/openwall/yescrypt-go"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Spring bcrypt only prefixes the id to a bcrypt hash.
+type SpringBcrypt struct {
+	PrefixPasswd
+}
+
+type SpringPBKDF2 struct {
+	Passwd
+	h func() hash.Hash
+}
+
+type SpringSCrypt struct {
+	Passwd
+	// The derived key length in bytes, which is implied by the stored key.
+	KeyLength int
+}
+
+type SpringSHA256 struct {
+	Passwd
+}
+
+type SpringNoop struct {
+	Passwd
+}
+
+// Make a Spring Security bcrypt password instance.
+func NewSpringBcryptPasswd() PasswdInterface {
+	return NewSpringBcryptWrapPasswd(NewBcryptVariantPasswd(BCRYPT_A_MAGIC))
+}
+
+// Make a Spring Security bcrypt password instance, which wraps a bcrypt password instance.
+func NewSpringBcryptWrapPasswd(crypt PasswdInterface) PasswdInterface {
+	return &SpringBcrypt{PrefixPasswd{PasswdInterface: crypt, Magic: SPRING_BCRYPT_MAGIC}}
+}
+
+// Make a Spring Security PBKDF2 password instance with the 5.8 defaults,
+// which is SHA256 with 310000 iterations and a 16 byte salt.
+func NewSpringPBKDF2Passwd() PasswdInterface {
+	return newSpringPBKDF2Passwd(sha256.New, 310000, 16)
+}
+
+// Make a Spring Security PBKDF2 password instance with the 5.5 defaults,
+// which is SHA1 with 185000 iterations and an 8 byte salt.
+func NewSpringPBKDF2SHA1Passwd() PasswdInterface {
+	return newSpringPBKDF2Passwd(sha1.New, 185000, 8)
+}
+
+// Make a Spring Security PBKDF2 password instance.
+func newSpringPBKDF2Passwd(h func() hash.Hash, iterations int, saltLength int) *SpringPBKDF2 {
+	m := new(SpringPBKDF2)
+	m.Magic = SPRING_PBKDF2_MAGIC
+	m.Params = strconv.Itoa(iterations)
+	// The salt is raw bytes, which are hex encoded with the digest.
+	m.SaltLength = saltLength
+	m.h = h
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a Spring Security scrypt password instance with the 5.8 defaults.
+func NewSpringSCryptPasswd() PasswdInterface {
+	m := new(SpringSCrypt)
+	m.Magic = SPRING_SCRYPT_MAGIC
+	m.SetSCryptParams(16, 8, 1)
+	m.KeyLength = 32
+	// The salt is raw bytes, which are base64 encoded.
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a Spring Security SHA256 password instance, the deprecated StandardPasswordEncoder.
+func NewSpringSHA256Passwd() PasswdInterface {
+	m := new(SpringSHA256)
+	m.Magic = SPRING_SHA256_MAGIC
+	// The salt is raw bytes, which are hex encoded with the digest.
+	m.SaltLength = 8
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a Spring Security plain text password instance.
+func NewSpringNoopPasswd() PasswdInterface {
+	m := new(SpringNoop)
+	m.Magic = SPRING_NOOP_MAGIC
+	// Plain text has no salt, so we disable it.
+	m.SaltLength = -1
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Generate a raw salt, as it is encoded with the digest.
+func (a *SpringPBKDF2) GenerateSalt() ([]byte, error) {
+	return generateRandomBytes(uint(a.SaltLength))
+}
+
+// Hash a password with salt using Spring's PBKDF2 encoder with a 256 bit hash width.
+func (a *SpringPBKDF2) Hash(password []byte, salt []byte, iterations int) (hash []byte, err error) {
+	if iterations < 1 {
+		err = errors.New("PBKDF2 iterations must be greater than 0")
+		return
+	}
+	key := pbkdf2.Key(password, salt, iterations, SHA256_SIZE, a.h)
+
+	// Create hash with result.
+	hash = []byte(a.Magic)
+	hash = append(hash, hex.EncodeToString(salt)...)
+	hash = append(hash, hex.EncodeToString(key)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with Spring's PBKDF2.
+func (a *SpringPBKDF2) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	iterations, err := strconv.Atoi(a.Params)
+	if err != nil {
+		return
+	}
+
+	hash, err = a.Hash(password, salt, iterations)
+	return
+}
+
+// Sets the scrypt params, N is the base 2 logarithm of the cost.
+func (a *SpringSCrypt) SetSCryptParams(N, r, p int) {
+	a.Params = strconv.FormatInt(int64(N)<<16|int64(r)<<8|int64(p), 16)
+}
+
+// Decode scrypt params.
+func (a *SpringSCrypt) DecodeSCryptParams() (N, r, p int, err error) {
+	params, err := strconv.ParseInt(a.Params, 16, 64)
+	if err != nil {
+		return
+	}
+	N = int(params >> 16 & 0xffff)
+	r = int(params >> 8 & 0xff)
+	p = int(params & 0xff)
+	return
+}
+
+// Sets the derived key length in bytes, the keyLength of Spring's encoder.
+func (a *SpringSCrypt) SetKeyLength(keyLength int) {
+	a.KeyLength = keyLength
+}
+
+// Generate a raw salt, as it is base64 encoded in the hash.
+func (a *SpringSCrypt) GenerateSalt() ([]byte, error) {
+	return generateRandomBytes(uint(a.SaltLength))
+}
+
+// Hash a password with salt using Spring's scrypt encoder.
+func (a *SpringSCrypt) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	N, r, p, err := a.DecodeSCryptParams()
+	if err != nil {
+		return
+	}
+	if N < 1 || N > 63 {
+		err = errors.New("scrypt N must be between 1 and 63")
+		return
+	}
+	if a.KeyLength < 1 {
+		err = errors.New("scrypt key length must be greater than 0")
+		return
+	}
+	key, err := yescrypt.ScryptKey(password, salt, 1<<N, r, p, a.KeyLength)
+	if err != nil {
+		return
+	}
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s$%s$%s$%s", a.Magic, a.Params,
+		base64.StdEncoding.EncodeToString(salt), base64.StdEncoding.EncodeToString(key)))
+	return
+}
+
+// Override the passwd hash with salt function to hash with Spring's scrypt.
+func (a *SpringSCrypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// Generate a raw salt, as it is encoded with the digest.
+func (a *SpringSHA256) GenerateSalt() ([]byte, error) {
+	return generateRandomBytes(uint(a.SaltLength))
+}
+
+// Hash a password with salt using 1024 iterations of SHA256.
+func (a *SpringSHA256) Hash(password []byte, salt []byte) (hash []byte) {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write(password)
+	buf := h.Sum(nil)
+	for i := 1; i < 1024; i++ {
+		h.Reset()
+		h.Write(buf)
+		buf = h.Sum(buf[:0])
+	}
+
+	// Create hash with result.
+	hash = []byte(a.Magic)
+	hash = append(hash, hex.EncodeToString(salt)...)
+	hash = append(hash, hex.EncodeToString(buf)...)
+	return
+}
+
+// Override the passwd hash with salt function to hash with Spring's SHA256.
+func (a *SpringSHA256) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash = a.Hash(password, salt)
+	return
+}
+
+// Override the passwd hash with salt function to store the password as is, ignoring the salt.
+func (a *SpringNoop) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash = append([]byte(a.Magic), password...)
+	return
+}
@@ -1,6 +1,8 @@
 package passwd
 
 import (
+	"bytes"
+	"encoding/base64"
 	"errors"
 	"hash"
 )
@@ -8,6 +10,18 @@ import (
 // The non-standard alphabet for crypt base64 encoding.
 const iota64Encoding = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 
+// The alphabet used by bcrypt, which is in standard base64 order unlike crypt base64.
+const bcrypt64Encoding = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// Base64 encoding used by bcrypt for the salt and hash.
+var bcryptBase64 = base64.NewEncoding(bcrypt64Encoding).WithPadding(base64.NoPadding)
+
+// Alphanumeric characters used for salts by Python frameworks.
+const alnumEncoding = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Cisco uses the crypt alphabet with the standard base64 bit order.
+var ciscoBase64 = base64.NewEncoding(iota64Encoding).WithPadding(base64.NoPadding)
+
 // Base64 to integer encoding table.
 var atoi64Partial = [...]byte{
 	0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
@@ -57,7 +71,7 @@ func Base64Encode(src []byte) []byte {
 
 // Takes a prior hash, and recycles bytes until the length provided is covered.
 func HashBlockRecycle(h hash.Hash, block []byte, len int) {
-	size := h.BlockSize()
+	size := h.Size()
 	var cnt int
 	for cnt = len; cnt > size; cnt -= size {
 		h.Write(block)
@@ -186,6 +200,25 @@ func SCryptBase64Decode(src []byte) []byte {
 	return dst
 }
 
+// Encode base64 in the adapted format used by passlib, which is standard
+// base64 without padding and with "." in place of "+".
+func AB64Encode(src []byte) []byte {
+	dst := make([]byte, base64.RawStdEncoding.EncodedLen(len(src)))
+	base64.RawStdEncoding.Encode(dst, src)
+	return bytes.ReplaceAll(dst, []byte("+"), []byte("."))
+}
+
+// Decode base64 in the adapted format used by passlib.
+func AB64Decode(src []byte) ([]byte, error) {
+	src = bytes.ReplaceAll(bytes.TrimRight(src, "="), []byte("."), []byte("+"))
+	dst := make([]byte, base64.RawStdEncoding.DecodedLen(len(src)))
+	n, err := base64.RawStdEncoding.Decode(dst, src)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n], nil
+}
+
 // Encode MD5 result to MD5 crypt base64.
 func MD5Base64Encode(src []byte) []byte {
 	// The way the crypt standards work with base64 encoding of MD5 is odd, because the
@@ -285,3 +318,68 @@ func Base64RotateEncode(src []byte, order bool) []byte {
 	// Return the base64.
 	return b64
 }
+
+// Encode base64 in the format used by AIX salted SHA hashes. Each group of 3
+// bytes is encoded big endian, least significant 6 bits first, with the last
+// group padded with zeros and truncated to the characters needed.
+func AIXBase64Encode(src []byte) []byte {
+	var b64 []byte
+	for i := 0; i < len(src); i += 3 {
+		var l uint
+		n := 1
+		for j := i; j < i+3; j++ {
+			l <<= 8
+			if j < len(src) {
+				l |= uint(src[j])
+				n++
+			}
+		}
+		b64 = Base64Append(b64, l, n)
+	}
+	return b64
+}
+
+// Encode base64 in the format used for bcrypt hashes.
+func BcryptBase64Encode(src []byte) []byte {
+	dst := make([]byte, bcryptBase64.EncodedLen(len(src)))
+	bcryptBase64.Encode(dst, src)
+	return dst
+}
+
+// Decode base64 in the format used for bcrypt hashes.
+func BcryptBase64Decode(src []byte) ([]byte, error) {
+	dst := make([]byte, bcryptBase64.DecodedLen(len(src)))
+	n, err := bcryptBase64.Decode(dst, src)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n], nil
+}
+
+// Encode base64 in the format used for Cisco type 8 and 9 secrets.
+func CiscoBase64Encode(src []byte) []byte {
+	dst := make([]byte, ciscoBase64.EncodedLen(len(src)))
+	ciscoBase64.Encode(dst, src)
+	return dst
+}
+
+// Encode a 64-bit DES result to 11 characters of crypt base64, most significant bits first.
+func DESBase64Encode(v uint64) []byte {
+	var b64 []byte
+	// The 64 bits are padded with 2 zero bits to evenly divide into 11 characters.
+	for shift := 58; shift > 0; shift -= 6 {
+		b64 = append(b64, iota64Encoding[(v>>uint(shift))&0x3F])
+	}
+	b64 = append(b64, iota64Encoding[(v<<2)&0x3F])
+	return b64
+}
+
+// Check that all characters are within the crypt base64 alphabet.
+func validIota64(src []byte) bool {
+	for _, c := range src {
+		if c < '.' || c > 'z' || atoi64Partial[c-'.'] > 63 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package passwd
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/openwall/yescrypt-go"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+type WerkzeugPBKDF2 struct {
+	Passwd
+}
+
+type WerkzeugSCrypt struct {
+	Passwd
+}
+
+// Make a Werkzeug PBKDF2 password instance, with SHA256 and 600000 iterations.
+func NewWerkzeugPBKDF2Passwd() PasswdInterface {
+	m := new(WerkzeugPBKDF2)
+	m.Magic = WERKZEUG_PBKDF2_MAGIC
+	m.SetPBKDF2Params("sha256", 600000)
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Make a Werkzeug scrypt password instance, with N=32768, r=8, and p=1.
+func NewWerkzeugSCryptPasswd() PasswdInterface {
+	m := new(WerkzeugSCrypt)
+	m.Magic = WERKZEUG_SCRYPT_MAGIC
+	m.SetSCryptParams(32768, 8, 1)
+	m.SaltLength = 16
+	// Set the interface to allow parents to call overriden functions.
+	m.i = m
+	return m
+}
+
+// Sets the PBKDF2 hash name, such as sha256, and iterations.
+func (a *WerkzeugPBKDF2) SetPBKDF2Params(hashName string, iterations int) {
+	a.Params = fmt.Sprintf("%s:%d", hashName, iterations)
+}
+
+// Decode PBKDF2 params.
+func (a *WerkzeugPBKDF2) DecodePBKDF2Params() (hashName string, iterations int, err error) {
+	s := strings.Split(a.Params, ":")
+	if len(s) != 2 {
+		err = errors.New("invalid Werkzeug PBKDF2 params")
+		return
+	}
+	hashName = s[0]
+	_, err = fmt.Sscanf(s[1], "%d", &iterations)
+	return
+}
+
+// Get the hash function and digest size for the hash name.
+func (a *WerkzeugPBKDF2) hashFunc(hashName string) (h func() hash.Hash, size int, err error) {
+	switch hashName {
+	case "sha1":
+		h, size = sha1.New, SHA1_SIZE
+	case "sha256":
+		h, size = sha256.New, SHA256_SIZE
+	case "sha512":
+		h, size = sha512.New, SHA512_SIZE
+	default:
+		err = errors.New("unsupported Werkzeug PBKDF2 hash")
+	}
+	return
+}
+
+// Generate an alphanumeric salt.
+func (a *WerkzeugPBKDF2) GenerateSalt() ([]byte, error) {
+	return generateAlnumSalt(a.SaltLength)
+}
+
+// Hash a password with salt using PBKDF2 in the Werkzeug format.
+func (a *WerkzeugPBKDF2) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	hashName, iterations, err := a.DecodePBKDF2Params()
+	if err != nil {
+		return
+	}
+	if iterations < 1 {
+		err = errors.New("PBKDF2 iterations must be greater than 0")
+		return
+	}
+
+	// The key length is the size of the digest.
+	h, size, err := a.hashFunc(hashName)
+	if err != nil {
+		return
+	}
+	key := pbkdf2.Key(password, salt, iterations, size, h)
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%s$%s$%s", a.Magic, a.Params, salt, hex.EncodeToString(key)))
+	return
+}
+
+// Override the passwd hash with salt function to hash with Werkzeug's PBKDF2.
+func (a *WerkzeugPBKDF2) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
+
+// Sets the scrypt params, N is the work factor.
+func (a *WerkzeugSCrypt) SetSCryptParams(N, r, p int) {
+	a.Params = fmt.Sprintf("%d:%d:%d", N, r, p)
+}
+
+// Decode scrypt params.
+func (a *WerkzeugSCrypt) DecodeSCryptParams() (N, r, p int, err error) {
+	_, err = fmt.Sscanf(a.Params, "%d:%d:%d", &N, &r, &p)
+	return
+}
+
+// Generate an alphanumeric salt.
+func (a *WerkzeugSCrypt) GenerateSalt() ([]byte, error) {
+	return generateAlnumSalt(a.SaltLength)
+}
+
+// Hash a password with salt using scrypt in the Werkzeug format.
+func (a *WerkzeugSCrypt) Hash(password []byte, salt []byte) (hash []byte, err error) {
+	N, r, p, err := a.DecodeSCryptParams()
+	if err != nil {
+		return
+	}
+	key, err := yescrypt.ScryptKey(password, salt, N, r, p, 64)
+	if err != nil {
+		return
+	}
+
+	// Create hash with result.
+	hash = []byte(fmt.Sprintf("%s%s$%s$%s", a.Magic, a.Params, salt, hex.EncodeToString(key)))
+	return
+}
+
+// Override the passwd hash with salt function to hash with Werkzeug's scrypt.
+func (a *WerkzeugSCrypt) HashPasswordWithSalt(password []byte, salt []byte) (hash []byte, err error) {
+	hash, err = a.Hash(password, salt)
+	return
+}
@@ -2,12 +2,11 @@ package passwd
 
 import (
 	"fmt"
-
-	"github.com/openwall/yescrypt-go"
 )
 
 type YesCrypt struct {
 	Passwd
+	ROM *YescryptROM
 }
 
 // Make an MD5Crypt password instance.
@@ -21,35 +20,58 @@ func NewYesCryptPasswd() PasswdInterface {
 	return m
 }
 
-// Sets the SCrypt params using integers.
+// Sets the SCrypt params using integers, which are the base 64 values of
+// the N log 2 minus 1 and r minus 1 as stored in the short params format.
 func (a *YesCrypt) SetSCryptParams(N, r int) (err error) {
-	Nval, err := IToA64(N)
+	params := DefaultYescryptParams()
+	params.N = 1 << uint(N+1)
+	params.R = uint32(r + 1)
+	return a.SetYescryptParams(params)
+}
+
+// Decode SCrypt params.
+func (a *YesCrypt) DecodeSCriptParams() (N, r int) {
+	params, err := a.DecodeYescryptParams()
 	if err != nil {
 		return
 	}
-	rval, err := IToA64(r)
+	N = N2log2(params.N) - 1
+	r = int(params.R) - 1
+	return
+}
+
+// Sets the full yescrypt params.
+func (a *YesCrypt) SetYescryptParams(params YescryptParams) (err error) {
+	p, err := params.Encode()
 	if err != nil {
 		return
 	}
-	a.Params = fmt.Sprintf("j%c%c", Nval, rval)
+	a.Params = p
 	return
 }
 
-// Decode SCrypt params.
-func (a *YesCrypt) DecodeSCriptParams() (N, r int) {
-	b64 := []byte(a.Params)
-	if len(b64) != 3 {
-		return
+// Sets the ROM used for hashes with params referencing one.
+func (a *YesCrypt) SetROM(rom *YescryptROM) {
+	a.ROM = rom
+}
+
+// Get the ROM set, or the default ROM if none is set.
+func (a *YesCrypt) yescryptROM() *YescryptROM {
+	if a.ROM != nil {
+		return a.ROM
 	}
-	N = AToI64(b64[1])
-	r = AToI64(b64[2])
-	return
+	return DefaultYescryptROM()
+}
+
+// Decode the full yescrypt params.
+func (a *YesCrypt) DecodeYescryptParams() (YescryptParams, error) {
+	return DecodeYescryptParams(a.Params)
 }
 
 // Hash a password with salt using yes crypt standard.
 func (a *YesCrypt) Hash(password []byte, salt []byte) (hash []byte, err error) {
 	output := fmt.Sprintf("%s%s$%s", a.Magic, a.Params, salt)
-	hash, err = yescrypt.Hash(password, []byte(output))
+	hash, err = yescryptHash(password, []byte(output), a.yescryptROM())
 	return
 }
 
@@ -0,0 +1,453 @@
+package passwd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Internal yescrypt flags, used while computing the pre-hash and building a ROM.
+const (
+	yescryptInitShared = 0x01000000
+	yescryptPrehash    = 0x10000000
+)
+
+// The pwxform settings used by the default flavor, in 32-bit words.
+const (
+	yescryptPWXsimple = 2
+	yescryptPWXgather = 4
+	yescryptPWXrounds = 6
+	yescryptSwidth    = 8
+	yescryptPWXwords  = yescryptPWXgather * yescryptPWXsimple * 2
+	yescryptSwords    = 3 * (1 << yescryptSwidth) * yescryptPWXsimple * 2
+	yescryptSmask     = ((1 << yescryptSwidth) - 1) * yescryptPWXsimple * 8
+)
+
+// The pwxform S-boxes and write position.
+type yescryptCtx struct {
+	S          []uint32
+	S0, S1, S2 []uint32
+	w          uint32
+}
+
+// Apply the salsa20 core to a block stored in the shuffled order.
+func yescryptSalsa20(B []uint32, rounds int) {
+	var x [16]uint32
+	for i := 0; i < 16; i++ {
+		x[i*5%16] = B[i]
+	}
+
+	for i := 0; i < rounds; i += 2 {
+		// Operate on columns.
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+
+		// Operate on rows.
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+
+	for i := 0; i < 16; i++ {
+		B[i] += x[i*5%16]
+	}
+}
+
+// XOR the words of src into dst.
+func yescryptBlockXOR(dst, src []uint32) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// The scrypt block mix with salsa20/8, using Y as scratch space.
+func yescryptBlockMixSalsa8(B, Y []uint32, r uint32) {
+	var X [16]uint32
+	copy(X[:], B[(2*r-1)*16:])
+	for i := uint32(0); i < 2*r; i++ {
+		yescryptBlockXOR(X[:], B[i*16:])
+		yescryptSalsa20(X[:], 8)
+		copy(Y[i*16:], X[:])
+	}
+
+	// Even blocks go in the first half, and odd blocks in the second half.
+	for i := uint32(0); i < r; i++ {
+		copy(B[i*16:(i+1)*16], Y[i*2*16:])
+		copy(B[(i+r)*16:(i+r+1)*16], Y[(i*2+1)*16:])
+	}
+}
+
+// Transform a block with the S-boxes, writing to S2 in the middle rounds.
+func yescryptPwxform(X []uint32, ctx *yescryptCtx) {
+	S0, S1, S2, w := ctx.S0, ctx.S1, ctx.S2, ctx.w
+
+	for i := 0; i < yescryptPWXrounds; i++ {
+		for j := 0; j < yescryptPWXgather; j++ {
+			xj := X[j*yescryptPWXsimple*2:]
+			p0 := S0[(xj[0]&yescryptSmask)/4:]
+			p1 := S1[(xj[1]&yescryptSmask)/4:]
+
+			for k := 0; k < yescryptPWXsimple; k++ {
+				s0 := uint64(p0[k*2+1])<<32 | uint64(p0[k*2])
+				s1 := uint64(p1[k*2+1])<<32 | uint64(p1[k*2])
+				x := uint64(xj[k*2+1]) * uint64(xj[k*2])
+				x += s0
+				x ^= s1
+				xj[k*2] = uint32(x)
+				xj[k*2+1] = uint32(x >> 32)
+			}
+
+			if i != 0 && i != yescryptPWXrounds-1 {
+				for k := 0; k < yescryptPWXsimple; k++ {
+					S2[w*2] = xj[k*2]
+					S2[w*2+1] = xj[k*2+1]
+					w++
+				}
+			}
+		}
+	}
+
+	ctx.S0, ctx.S1, ctx.S2 = S2, S0, S1
+	ctx.w = w & ((1<<yescryptSwidth)*yescryptPWXsimple - 1)
+}
+
+// The yescrypt block mix with pwxform.
+func yescryptBlockMixPwxform(B []uint32, ctx *yescryptCtx, r uint32) {
+	var X [yescryptPWXwords]uint32
+	r1 := 128 * r / (yescryptPWXwords * 4)
+	copy(X[:], B[(r1-1)*yescryptPWXwords:])
+	for i := uint32(0); i < r1; i++ {
+		if r1 > 1 {
+			yescryptBlockXOR(X[:], B[i*yescryptPWXwords:])
+		}
+		yescryptPwxform(X[:], ctx)
+		copy(B[i*yescryptPWXwords:], X[:])
+	}
+
+	// Mix the last sub-block, and any after it.
+	i := (r1 - 1) * yescryptPWXwords / 16
+	yescryptSalsa20(B[i*16:], 2)
+	for i++; i < 2*r; i++ {
+		yescryptBlockXOR(B[i*16:(i+1)*16], B[(i-1)*16:])
+		yescryptSalsa20(B[i*16:], 2)
+	}
+}
+
+// Get the index value from the last 64 bytes of a block.
+func yescryptIntegerify(B []uint32, r uint32) uint32 {
+	return B[(2*r-1)*16]
+}
+
+// Wrap an index to the blocks written so far.
+func yescryptWrap(x, i uint32) uint32 {
+	n := p2floor(i)
+	return (x & (n - 1)) + (i - n)
+}
+
+// Get the largest power of 2 not greater than x.
+func p2floor(x uint32) uint32 {
+	for x&(x-1) != 0 {
+		x &= x - 1
+	}
+	return x
+}
+
+// Read a block from bytes into the shuffled word order.
+func yescryptBlockDecode(X []uint32, B []byte, r uint32) {
+	for k := uint32(0); k < 2*r; k++ {
+		for i := uint32(0); i < 16; i++ {
+			X[k*16+i] = binary.LittleEndian.Uint32(B[(k*16+i*5%16)*4:])
+		}
+	}
+}
+
+// Write a block from the shuffled word order back to bytes.
+func yescryptBlockEncode(B []byte, X []uint32, r uint32) {
+	for k := uint32(0); k < 2*r; k++ {
+		for i := uint32(0); i < 16; i++ {
+			binary.LittleEndian.PutUint32(B[(k*16+i*5%16)*4:], X[k*16+i])
+		}
+	}
+}
+
+// Mix a block, filling V. Odd iterations read from the ROM when provided.
+func yescryptSmix1(B []byte, r, N, flags uint32, V []uint32, NROM uint32, VROM []uint32, XY []uint32, ctx *yescryptCtx) {
+	s := 32 * r
+	X := XY[:s]
+	Y := XY[s : 2*s]
+	yescryptBlockDecode(X, B, r)
+
+	for i := uint32(0); i < N; i++ {
+		copy(V[i*s:(i+1)*s], X)
+		if VROM != nil && i&1 != 0 {
+			j := yescryptIntegerify(X, r) & (NROM - 1)
+			yescryptBlockXOR(X, VROM[j*s:])
+		} else if flags&YESCRYPT_RW != 0 && i > 1 {
+			j := yescryptWrap(yescryptIntegerify(X, r), i)
+			yescryptBlockXOR(X, V[j*s:])
+		}
+
+		if ctx != nil {
+			yescryptBlockMixPwxform(X, ctx, r)
+		} else {
+			yescryptBlockMixSalsa8(X, Y, r)
+		}
+	}
+
+	yescryptBlockEncode(B, X, r)
+}
+
+// Mix a block with random reads from V, and writes when in RW mode.
+// Odd iterations read from the ROM when provided.
+func yescryptSmix2(B []byte, r, N uint32, Nloop uint64, flags uint32, V []uint32, NROM uint32, VROM []uint32, XY []uint32, ctx *yescryptCtx) {
+	if Nloop == 0 {
+		return
+	}
+	s := 32 * r
+	X := XY[:s]
+	Y := XY[s : 2*s]
+	yescryptBlockDecode(X, B, r)
+
+	for i := uint64(0); i < Nloop; i++ {
+		if VROM != nil && i&1 != 0 {
+			j := yescryptIntegerify(X, r) & (NROM - 1)
+			yescryptBlockXOR(X, VROM[j*s:])
+		} else {
+			j := yescryptIntegerify(X, r) & (N - 1)
+			yescryptBlockXOR(X, V[j*s:])
+			if flags&YESCRYPT_RW != 0 {
+				copy(V[j*s:(j+1)*s], X)
+			}
+		}
+
+		if ctx != nil {
+			yescryptBlockMixPwxform(X, ctx, r)
+		} else {
+			yescryptBlockMixSalsa8(X, Y, r)
+		}
+	}
+
+	yescryptBlockEncode(B, X, r)
+}
+
+// Mix the p blocks of B, with the loop counts set by t and the flags.
+func yescryptSmix(B []byte, r, N, p, t, flags uint32, V []uint32, NROM uint32, VROM []uint32, XY []uint32, ctx []yescryptCtx, passwd []byte) {
+	s := 32 * r
+	Nchunk := N / p
+
+	// The number of loops depends on the time cost and mode.
+	NloopAll := uint64(Nchunk)
+	if flags&YESCRYPT_RW != 0 {
+		if t <= 1 {
+			if t != 0 {
+				NloopAll *= 2
+			}
+			NloopAll = (NloopAll + 2) / 3
+		} else {
+			NloopAll *= uint64(t - 1)
+		}
+	} else if t != 0 {
+		if t == 1 {
+			NloopAll += (NloopAll + 1) / 2
+		}
+		NloopAll *= uint64(t)
+	}
+
+	var NloopRW uint64
+	if flags&yescryptInitShared != 0 {
+		NloopRW = NloopAll
+	} else if flags&YESCRYPT_RW != 0 {
+		NloopRW = NloopAll / uint64(p)
+	}
+
+	// Round the chunk down, and the loops up to even numbers.
+	Nchunk &^= 1
+	NloopAll = (NloopAll + 1) &^ 1
+	NloopRW = (NloopRW + 1) &^ 1
+
+	for i := uint32(0); i < p; i++ {
+		Vchunk := i * Nchunk
+		Np := N - Vchunk
+		if i < p-1 {
+			Np = Nchunk
+		}
+		Bp := B[i*128*r:]
+		Vp := V[Vchunk*s:]
+
+		var ctxI *yescryptCtx
+		if flags&YESCRYPT_RW != 0 {
+			// Fill the S-boxes with scrypt of the block.
+			ctxI = &ctx[i]
+			yescryptSmix1(Bp, 1, yescryptSwords/32, 0, ctxI.S, 0, nil, XY, nil)
+			ctxI.S2 = ctxI.S
+			ctxI.S1 = ctxI.S[(1<<yescryptSwidth)*yescryptPWXsimple*2:]
+			ctxI.S0 = ctxI.S1[(1<<yescryptSwidth)*yescryptPWXsimple*2:]
+			ctxI.w = 0
+
+			if i == 0 {
+				h := hmac.New(sha256.New, Bp[128*r-64:128*r])
+				h.Write(passwd)
+				copy(passwd, h.Sum(nil))
+			}
+		}
+		yescryptSmix1(Bp, r, Np, flags, Vp, NROM, VROM, XY, ctxI)
+		yescryptSmix2(Bp, r, p2floor(Np), NloopRW, flags, Vp, NROM, VROM, XY, ctxI)
+	}
+
+	for i := uint32(0); i < p; i++ {
+		var ctxI *yescryptCtx
+		if flags&YESCRYPT_RW != 0 {
+			ctxI = &ctx[i]
+		}
+		yescryptSmix2(B[i*128*r:], r, N, NloopAll-NloopRW, flags&^YESCRYPT_RW, V, NROM, VROM, XY, ctxI)
+	}
+}
+
+// Derive a key with yescrypt, without the pre-hash for large N.
+// V is used for the memory when building a ROM, otherwise it is allocated.
+func yescryptKDFBody(VROM []uint32, V []uint32, password, salt []byte, flags uint32, N uint64, r, p, t uint32, NROM uint64, keyLen int) ([]byte, error) {
+	switch flags & 0x3 {
+	case 0:
+		// Classic scrypt can't have anything non-standard.
+		if flags != 0 || t != 0 || NROM != 0 {
+			return nil, errors.New("unsupported params for classic scrypt")
+		}
+	case YESCRYPT_WORM:
+		if flags != YESCRYPT_WORM || NROM != 0 {
+			return nil, errors.New("unsupported params for yescrypt WORM")
+		}
+	case YESCRYPT_RW:
+		if flags&^(yescryptInitShared|yescryptPrehash) != YESCRYPT_DEFAULTS {
+			return nil, errors.New("unsupported yescrypt flags")
+		}
+	default:
+		return nil, errors.New("unsupported yescrypt flags")
+	}
+
+	if N <= 1 || N&(N-1) != 0 || r < 1 || p < 1 {
+		return nil, errors.New("invalid yescrypt params")
+	}
+	if uint64(r)*uint64(p) >= 1<<30 || N > 1<<32-1 || 128*uint64(r)*N > 1<<40 {
+		return nil, errors.New("yescrypt params are too large")
+	}
+	if flags&YESCRYPT_RW != 0 && N/uint64(p) <= 1 {
+		return nil, errors.New("yescrypt N is too small for p")
+	}
+
+	// A ROM is required exactly when the params reference one.
+	if VROM != nil {
+		if NROM <= 1 || NROM&(NROM-1) != 0 || uint64(len(VROM)) < 32*uint64(r)*NROM {
+			return nil, errors.New("yescrypt ROM does not match the params")
+		}
+		if flags&yescryptInitShared == 0 && !yescryptROMTagged(VROM[:32*uint64(r)*NROM]) {
+			return nil, errors.New("yescrypt ROM is not initialized")
+		}
+	} else if NROM != 0 {
+		return nil, errors.New("yescrypt ROM is required by the params")
+	}
+
+	if V == nil {
+		V = make([]uint32, 32*uint64(r)*N)
+	} else if uint64(len(V)) < 32*uint64(r)*N {
+		return nil, errors.New("yescrypt memory is too small")
+	}
+	XY := make([]uint32, 64*r)
+	var ctx []yescryptCtx
+	if flags&YESCRYPT_RW != 0 {
+		ctx = make([]yescryptCtx, p)
+		for i := range ctx {
+			ctx[i].S = make([]uint32, yescryptSwords)
+		}
+	}
+
+	// Other than classic scrypt, the password is hashed first.
+	if flags != 0 {
+		key := []byte("yescrypt-prehash")
+		if flags&yescryptPrehash == 0 {
+			key = key[:8]
+		}
+		h := hmac.New(sha256.New, key)
+		h.Write(password)
+		password = h.Sum(nil)
+	}
+
+	B := pbkdf2.Key(password, salt, 1, int(128*r*p), sha256.New)
+	if flags != 0 {
+		password = append([]byte{}, B[:32]...)
+	}
+
+	if flags&YESCRYPT_RW != 0 {
+		yescryptSmix(B, r, uint32(N), p, t, flags, V, uint32(NROM), VROM, XY, ctx, password)
+	} else {
+		for i := uint32(0); i < p; i++ {
+			yescryptSmix(B[i*128*r:], r, uint32(N), 1, t, flags, V, uint32(NROM), VROM, XY, nil, nil)
+		}
+	}
+
+	key := pbkdf2.Key(password, B, 1, max(keyLen, 32), sha256.New)
+
+	// Finish like SCRAM, allowing the computation so far to be done by a client.
+	if flags != 0 && flags&yescryptPrehash == 0 {
+		h := hmac.New(sha256.New, key[:32])
+		h.Write([]byte("Client Key"))
+		storedKey := sha256.Sum256(h.Sum(nil))
+		copy(key, storedKey[:])
+	}
+	return key[:keyLen], nil
+}
+
+// Derive a key with yescrypt. For large N, the password is first pre-hashed
+// with a smaller N to reduce the cost of denial of service attacks.
+func yescryptKDF(VROM []uint32, password, salt []byte, params YescryptParams, keyLen int) ([]byte, error) {
+	// Support for hash upgrades is not in the reference implementation.
+	if params.G != 0 {
+		return nil, errors.New("yescrypt hash upgrades are not supported")
+	}
+
+	N, r, p := params.N, params.R, params.P
+	if params.Flags&YESCRYPT_RW != 0 && p >= 1 && N/uint64(p) >= 0x100 && N/uint64(p)*uint64(r) >= 0x20000 {
+		dk, err := yescryptKDFBody(VROM, nil, password, salt, params.Flags|yescryptPrehash, N>>6, r, p, 0, params.NROM, 32)
+		if err != nil {
+			return nil, err
+		}
+		password = dk
+	}
+	return yescryptKDFBody(VROM, nil, password, salt, params.Flags, N, r, p, params.T, params.NROM, keyLen)
+}
@@ -0,0 +1,285 @@
+package passwd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Yescrypt flags, matching the reference implementation.
+const (
+	YESCRYPT_WORM           = 0x001
+	YESCRYPT_RW             = 0x002
+	YESCRYPT_ROUNDS_6       = 0x004
+	YESCRYPT_GATHER_4       = 0x010
+	YESCRYPT_SIMPLE_2       = 0x020
+	YESCRYPT_SBOX_12K       = 0x080
+	YESCRYPT_RW_FLAVOR_MASK = 0x3fc
+	YESCRYPT_DEFAULTS       = YESCRYPT_RW | YESCRYPT_ROUNDS_6 | YESCRYPT_GATHER_4 | YESCRYPT_SIMPLE_2 | YESCRYPT_SBOX_12K
+)
+
+// The yescrypt cost parameters, as encoded before the salt.
+type YescryptParams struct {
+	// Flags selecting classic scrypt, WORM, or the RW flavor.
+	Flags uint32
+	// Block count, a power of 2.
+	N uint64
+	// Block size.
+	R uint32
+	// Parallelism.
+	P uint32
+	// Additional time cost.
+	T uint32
+	// Number of times the hash was upgraded.
+	G uint32
+	// Block count of the ROM, a power of 2 or 0 for no ROM.
+	NROM uint64
+}
+
+// The default params used by libxcrypt.
+func DefaultYescryptParams() YescryptParams {
+	return YescryptParams{
+		Flags: YESCRYPT_DEFAULTS,
+		N:     4096,
+		R:     32,
+		P:     1,
+	}
+}
+
+// Check the params only use the default flags without optional params,
+// which is the short form supported by the yescrypt package.
+func yescryptShortForm(p YescryptParams) bool {
+	return p.Flags == YESCRYPT_DEFAULTS && p.P == 1 && p.T == 0 && p.G == 0 && p.NROM == 0
+}
+
+// Encode a uint32 with the variable length yescrypt encoding. The first
+// character chooses the length, with larger values using more characters.
+func yescryptEncode64Uint32(dst []byte, src uint32, min uint32) ([]byte, error) {
+	if src < min {
+		return nil, errors.New("yescrypt parameter is too small")
+	}
+	src -= min
+
+	start, end, chars, bits := uint32(0), uint32(47), 1, uint(0)
+	for {
+		count := (end + 1 - start) << bits
+		if src < count {
+			break
+		}
+		if start >= 63 {
+			return nil, errors.New("yescrypt parameter is too large")
+		}
+		start = end + 1
+		end = start + (62-end)/2
+		src -= count
+		chars++
+		bits += 6
+	}
+
+	dst = append(dst, iota64Encoding[start+(src>>bits)])
+	for chars--; chars > 0; chars-- {
+		bits -= 6
+		dst = append(dst, iota64Encoding[(src>>bits)&0x3f])
+	}
+	return dst, nil
+}
+
+// Decode a uint32 with the variable length yescrypt encoding, returning the remaining source.
+func yescryptDecode64Uint32(src []byte, min uint32) (dst uint32, rest []byte, err error) {
+	if len(src) == 0 || !validIota64(src[:1]) {
+		err = errors.New("invalid yescrypt parameter encoding")
+		return
+	}
+	c := uint32(AToI64(src[0]))
+	src = src[1:]
+
+	start, end, chars, bits := uint32(0), uint32(47), 1, uint(0)
+	dst = min
+	for c > end {
+		dst += (end + 1 - start) << bits
+		start = end + 1
+		end = start + (62-end)/2
+		chars++
+		bits += 6
+	}
+	dst += (c - start) << bits
+
+	for chars--; chars > 0; chars-- {
+		if len(src) == 0 || !validIota64(src[:1]) {
+			err = errors.New("invalid yescrypt parameter encoding")
+			return
+		}
+		bits -= 6
+		dst += uint32(AToI64(src[0])) << bits
+		src = src[1:]
+	}
+	rest = src
+	return
+}
+
+// Encode the params, in the format used between the magic and the salt.
+func (p YescryptParams) Encode() (string, error) {
+	// The flavor packs the RW flags, which skip the mode bits.
+	var flavor uint32
+	if p.Flags < YESCRYPT_RW {
+		flavor = p.Flags
+	} else if p.Flags&0x3 == YESCRYPT_RW && p.Flags <= YESCRYPT_RW|YESCRYPT_RW_FLAVOR_MASK {
+		flavor = YESCRYPT_RW + p.Flags>>2
+	} else {
+		return "", errors.New("unsupported yescrypt flags")
+	}
+
+	NLog2 := N2log2(p.N)
+	if NLog2 == 0 {
+		return "", errors.New("yescrypt N must be a power of 2 greater than 1")
+	}
+	NROMLog2 := N2log2(p.NROM)
+	if p.NROM != 0 && NROMLog2 == 0 {
+		return "", errors.New("yescrypt NROM must be a power of 2 greater than 1")
+	}
+	if uint64(p.R)*uint64(p.P) >= 1<<30 {
+		return "", errors.New("yescrypt r and p are too large")
+	}
+
+	// Flags mark which of the optional params follow.
+	var have uint32
+	if p.P != 1 {
+		have |= 1
+	}
+	if p.T != 0 {
+		have |= 2
+	}
+	if p.G != 0 {
+		have |= 4
+	}
+	if NROMLog2 != 0 {
+		have |= 8
+	}
+
+	dst, err := yescryptEncode64Uint32(nil, flavor, 0)
+	if err != nil {
+		return "", err
+	}
+	dst, err = yescryptEncode64Uint32(dst, uint32(NLog2), 1)
+	if err != nil {
+		return "", err
+	}
+	dst, err = yescryptEncode64Uint32(dst, p.R, 1)
+	if err != nil {
+		return "", err
+	}
+	if have != 0 {
+		dst, err = yescryptEncode64Uint32(dst, have, 1)
+		if err != nil {
+			return "", err
+		}
+	}
+	if have&1 != 0 {
+		dst, err = yescryptEncode64Uint32(dst, p.P, 2)
+		if err != nil {
+			return "", err
+		}
+	}
+	if have&2 != 0 {
+		dst, err = yescryptEncode64Uint32(dst, p.T, 1)
+		if err != nil {
+			return "", err
+		}
+	}
+	if have&4 != 0 {
+		dst, err = yescryptEncode64Uint32(dst, p.G, 1)
+		if err != nil {
+			return "", err
+		}
+	}
+	if have&8 != 0 {
+		dst, err = yescryptEncode64Uint32(dst, uint32(NROMLog2), 1)
+		if err != nil {
+			return "", err
+		}
+	}
+	return string(dst), nil
+}
+
+// Decode params in the format used between the magic and the salt.
+func DecodeYescryptParams(params string) (p YescryptParams, err error) {
+	src := []byte(params)
+	p.P = 1
+
+	var flavor uint32
+	flavor, src, err = yescryptDecode64Uint32(src, 0)
+	if err != nil {
+		return
+	}
+	if flavor < YESCRYPT_RW {
+		p.Flags = flavor
+	} else if flavor <= YESCRYPT_RW+YESCRYPT_RW_FLAVOR_MASK>>2 {
+		p.Flags = YESCRYPT_RW + (flavor-YESCRYPT_RW)<<2
+	} else {
+		err = errors.New("unsupported yescrypt flavor")
+		return
+	}
+
+	var NLog2 uint32
+	NLog2, src, err = yescryptDecode64Uint32(src, 1)
+	if err != nil {
+		return
+	}
+	if NLog2 > 63 {
+		err = errors.New("yescrypt N is too large")
+		return
+	}
+	p.N = 1 << NLog2
+
+	p.R, src, err = yescryptDecode64Uint32(src, 1)
+	if err != nil {
+		return
+	}
+
+	// Optional params follow when present.
+	if len(src) != 0 {
+		var have uint32
+		have, src, err = yescryptDecode64Uint32(src, 1)
+		if err != nil {
+			return
+		}
+		if have&^0xf != 0 {
+			err = errors.New("unsupported yescrypt params")
+			return
+		}
+		if have&1 != 0 {
+			p.P, src, err = yescryptDecode64Uint32(src, 2)
+			if err != nil {
+				return
+			}
+		}
+		if have&2 != 0 {
+			p.T, src, err = yescryptDecode64Uint32(src, 1)
+			if err != nil {
+				return
+			}
+		}
+		if have&4 != 0 {
+			p.G, src, err = yescryptDecode64Uint32(src, 1)
+			if err != nil {
+				return
+			}
+		}
+		if have&8 != 0 {
+			var NROMLog2 uint32
+			NROMLog2, src, err = yescryptDecode64Uint32(src, 1)
+			if err != nil {
+				return
+			}
+			if NROMLog2 > 63 {
+				err = errors.New("yescrypt NROM is too large")
+				return
+			}
+			p.NROM = 1 << NROMLog2
+		}
+	}
+
+	if len(src) != 0 {
+		err = fmt.Errorf("unexpected characters after yescrypt params: %s", src)
+	}
+	return
+}
@@ -0,0 +1,202 @@
+package passwd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/openwall/yescrypt-go"
+)
+
+// The tag written to the end of an initialized ROM, "yescrypt-ROMhash".
+const (
+	yescryptROMTag1 = 0x7470797263736579
+	yescryptROMTag2 = 0x687361684d4f522d
+)
+
+// A yescrypt ROM, a large read-only lookup table used for server-side hashing.
+// It is not modified after creation, so it can be shared between goroutines.
+type YescryptROM struct {
+	NROM uint64
+	R    uint32
+	v    []uint32
+}
+
+// The package default ROM used when a yescrypt instance has none set.
+var defaultYescryptROM atomic.Pointer[YescryptROM]
+
+// Sets the ROM used for yescrypt hashes when an instance has none set,
+// such as when checking hashes with CheckPassword. A nil ROM clears it.
+func SetDefaultYescryptROM(rom *YescryptROM) {
+	defaultYescryptROM.Store(rom)
+}
+
+// Get the default yescrypt ROM.
+func DefaultYescryptROM() *YescryptROM {
+	return defaultYescryptROM.Load()
+}
+
+// Check a ROM ends with the initialized tag.
+func yescryptROMTagged(v []uint32) bool {
+	if len(v) < 12 {
+		return false
+	}
+	tag := v[len(v)-12:]
+	return uint64(tag[1])<<32|uint64(tag[0]) == yescryptROMTag1 &&
+		uint64(tag[3])<<32|uint64(tag[2]) == yescryptROMTag2
+}
+
+// Check the ROM size is usable with block size r.
+func yescryptCheckROMSize(NROM uint64, r uint32) error {
+	if r < 1 || NROM <= 1 || NROM&(NROM-1) != 0 {
+		return errors.New("yescrypt ROM size must be a power of 2 greater than 1")
+	}
+	if NROM > 1<<32-1 || 128*uint64(r)*NROM > 1<<40 {
+		return errors.New("yescrypt ROM is too large")
+	}
+	return nil
+}
+
+// Build a ROM of NROM blocks of 128*r bytes, deterministically generated from the seed.
+func NewYescryptROM(seed []byte, NROM uint64, r uint32) (rom *YescryptROM, err error) {
+	err = yescryptCheckROMSize(NROM, r)
+	if err != nil {
+		return
+	}
+	v := make([]uint32, 32*uint64(r)*NROM)
+	half1 := v[:len(v)/2]
+	half2 := v[len(v)/2:]
+	flags := uint32(YESCRYPT_DEFAULTS | yescryptInitShared)
+
+	// Fill the whole ROM, then hash each half using the other as the ROM.
+	salt, err := yescryptKDFBody(nil, v, seed, []byte("yescrypt-ROMhash"), flags, NROM, r, 1, 0, 0, 32)
+	if err != nil {
+		return
+	}
+	salt, err = yescryptKDFBody(half1, half2, seed, salt, flags, NROM/2, r, 1, 0, NROM/2, 32)
+	if err != nil {
+		return
+	}
+	salt, err = yescryptKDFBody(half2, half1, seed, salt, flags, NROM/2, r, 1, 0, NROM/2, 32)
+	if err != nil {
+		return
+	}
+
+	// Tag the end of the ROM with the final salt.
+	tag := v[len(v)-12:]
+	tag[0], tag[1] = yescryptROMTag1&0xffffffff, yescryptROMTag1>>32
+	tag[2], tag[3] = yescryptROMTag2&0xffffffff, yescryptROMTag2>>32
+	for i := 0; i < 8; i++ {
+		tag[4+i] = binary.LittleEndian.Uint32(salt[i*4:])
+	}
+
+	rom = &YescryptROM{NROM: NROM, R: r, v: v}
+	return
+}
+
+// Read a ROM previously written with WriteTo, using block size r.
+func ReadYescryptROM(rd io.Reader, r uint32) (rom *YescryptROM, err error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return
+	}
+	if r < 1 || len(data) == 0 || uint64(len(data))%(128*uint64(r)) != 0 {
+		err = errors.New("yescrypt ROM size is not a multiple of the block size")
+		return
+	}
+	NROM := uint64(len(data)) / (128 * uint64(r))
+	err = yescryptCheckROMSize(NROM, r)
+	if err != nil {
+		return
+	}
+
+	v := make([]uint32, len(data)/4)
+	for i := range v {
+		v[i] = binary.LittleEndian.Uint32(data[i*4:])
+	}
+	if !yescryptROMTagged(v) {
+		err = errors.New("yescrypt ROM is not initialized")
+		return
+	}
+	rom = &YescryptROM{NROM: NROM, R: r, v: v}
+	return
+}
+
+// Load a ROM from a file, using block size r.
+func LoadYescryptROM(path string, r uint32) (rom *YescryptROM, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	rom, err = ReadYescryptROM(f, r)
+	return
+}
+
+// Write the ROM so it can be loaded instead of rebuilt.
+func (rom *YescryptROM) WriteTo(w io.Writer) (n int64, err error) {
+	buf := make([]byte, 4*len(rom.v))
+	for i, x := range rom.v {
+		binary.LittleEndian.PutUint32(buf[i*4:], x)
+	}
+	return bytes.NewReader(buf).WriteTo(w)
+}
+
+// Set the NROM of params to use this ROM.
+func (rom *YescryptROM) Params(params YescryptParams) YescryptParams {
+	params.NROM = rom.NROM
+	params.R = rom.R
+	return params
+}
+
+// Hash a password with a yescrypt setting of "$y$params$salt", using the ROM
+// if the params reference one.
+func yescryptHash(password, setting []byte, rom *YescryptROM) (hash []byte, err error) {
+	parts := bytes.Split(setting, []byte{'$'})
+	if len(parts) < 4 || len(parts[0]) != 0 {
+		err = errors.New("invalid yescrypt setting")
+		return
+	}
+	saltEnd := len(parts[0]) + len(parts[1]) + len(parts[2]) + len(parts[3]) + 3
+	params, err := DecodeYescryptParams(string(parts[2]))
+	if err != nil {
+		return
+	}
+	salt := SCryptBase64Decode(parts[3])
+	if salt == nil {
+		err = errors.New("invalid yescrypt salt encoding")
+		return
+	}
+
+	// Use the yescrypt package where it supports the params.
+	if yescryptShortForm(params) {
+		hash, err = yescrypt.Hash(password, setting[:saltEnd])
+		return
+	}
+
+	var VROM []uint32
+	if params.NROM != 0 {
+		if rom == nil {
+			err = errors.New("yescrypt hash requires a ROM, which is not loaded")
+			return
+		}
+		if rom.NROM != params.NROM || rom.R != params.R {
+			err = fmt.Errorf("yescrypt hash requires a ROM with NROM %d and r %d, but the loaded ROM has NROM %d and r %d", params.NROM, params.R, rom.NROM, rom.R)
+			return
+		}
+		VROM = rom.v
+	}
+
+	key, err := yescryptKDF(VROM, password, salt, params, 32)
+	if err != nil {
+		return
+	}
+	hash = append([]byte{}, setting[:saltEnd]...)
+	hash = append(hash, '$')
+	hash = append(hash, SCryptBase64Encode(key)...)
+	return
+}